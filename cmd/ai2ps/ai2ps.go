@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -21,12 +22,13 @@ func main() {
 		*output = strings.TrimSuffix(*input, ".ai") + "-ai.ps"
 	}
 
-	r, err := illustrator.NewFileReader(*input)
+	f, err := illustrator.Open(*input)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer f.Close()
 
-	priData, err := r.GetAIPrivateData()
+	priData, err := f.PrivateData()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -37,5 +39,7 @@ func main() {
 	}
 	defer file.Close()
 
-	file.Write(priData)
+	if _, err := io.Copy(file, priData); err != nil {
+		log.Fatal(err)
+	}
 }
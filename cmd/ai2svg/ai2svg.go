@@ -1,9 +1,11 @@
 package main
 
 import (
-	"bytes"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/fpagyu/illustrator"
@@ -11,8 +13,11 @@ import (
 )
 
 var (
-	input  = flag.String("i", "", "-i <input file path>")
-	output = flag.String("o", "", "-o <output file path>")
+	input    = flag.String("i", "", "-i <input file path>")
+	output   = flag.String("o", "", "-o <output file path>")
+	guides   = flag.String("guides", "", "-guides <guides json file path>")
+	progress = flag.Bool("progress", false, "-progress, print the progress to stderr")
+	password = flag.String("password", "", "-password <password of encrypted files>")
 )
 
 func main() {
@@ -28,33 +33,56 @@ func main() {
 		*output = strings.TrimSuffix(*input, ".ai") + "-ai.svg"
 	}
 
-	r, err := NewReader(*input)
+	file, err := illustrator.Open(*input, illustrator.WithPassword(*password))
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer file.Close()
 
-	var svg svg.SVG
-	err = r.Draw(&svg)
-	if err != nil {
+	// files saved without illustrator editing capabilities are drawn from
+	// the pdf page content
+	r, err := file.AIReader()
+	if err != nil && (!errors.Is(err, illustrator.ErrNoPrivateData) || file.PDF == nil) {
 		log.Fatal(err)
 	}
 
-	err = svg.Save(*output)
-	if err != nil {
-		log.Fatal(err)
+	var options []func(*svg.SvgWriteOption)
+	if *progress && r != nil {
+		r.SetProgress(func(p illustrator.Progress) {
+			if p.Total > 0 {
+				fmt.Fprintf(os.Stderr, "\rreading %3d%% %s\033[K", p.Read*100/p.Total, p.Layer)
+			} else {
+				fmt.Fprintf(os.Stderr, "\rreading %d bytes %s\033[K", p.Read, p.Layer)
+			}
+		})
+		options = append(options, svg.SetProgress(func(written, total int) {
+			if total > 0 {
+				fmt.Fprintf(os.Stderr, "\rwriting %3d%%\033[K", written*100/total)
+			}
+		}))
 	}
-}
 
-func NewReader(path string) (*illustrator.AIReader, error) {
-	r, err := illustrator.NewFileReader(path)
+	var doc svg.SVG
+	if r != nil {
+		err = r.DrawV2(doc.V2())
+	} else {
+		err = file.PDF.DrawContent(doc.V2())
+	}
 	if err != nil {
-		return nil, err
+		log.Fatal(err)
 	}
 
-	data, err := r.GetAIPrivateData()
+	err = doc.Save(*output, options...)
+	if *progress {
+		fmt.Fprintln(os.Stderr)
+	}
 	if err != nil {
-		return nil, err
+		log.Fatal(err)
 	}
 
-	return illustrator.NewAIReader(bytes.NewBuffer(data))
+	if len(*guides) > 0 {
+		if err := doc.SaveGuides(*guides); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpagyu/illustrator"
+)
+
+var (
+	input  = flag.String("i", "", "-i <input file path>")
+	output = flag.String("o", "", "-o <output file path>, .jpg or .png")
+)
+
+func main() {
+	flag.Parse()
+
+	if len(*output) == 0 {
+		*output = strings.TrimSuffix(*input, ".ai") + "-thumb.jpg"
+	}
+
+	f, err := illustrator.Open(*input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.PDF == nil {
+		log.Fatal(illustrator.ErrNoThumbnail)
+	}
+
+	ext := strings.ToLower(filepath.Ext(*output))
+	isJPEG := ext == ".jpg" || ext == ".jpeg"
+
+	// the xmp thumbnail is copied without decoding, Thumbnail falls back
+	// to the page thumbnail
+	if isJPEG {
+		data, err := f.PDF.ThumbnailJPEG()
+		if err == nil {
+			if err := os.WriteFile(*output, data, 0644); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	img, err := f.PDF.Thumbnail()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if isJPEG {
+		err = jpeg.Encode(file, img, nil)
+	} else {
+		err = png.Encode(file, img)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fpagyu/illustrator"
+)
+
+var (
+	input    = flag.String("i", "", "-i <input file path>")
+	password = flag.String("password", "", "-password <password of encrypted files>")
+)
+
+// prints the diagnosis of the private data, exits with 1 when problems
+// are found
+func main() {
+	flag.Parse()
+
+	f, err := illustrator.Open(*input, illustrator.WithPassword(*password))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.PDF == nil {
+		log.Fatal("the input is not a pdf compatible ai file")
+	}
+
+	rep, err := f.PDF.Inspect()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(rep)
+	if !rep.OK() {
+		os.Exit(1)
+	}
+}
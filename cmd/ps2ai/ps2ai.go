@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fpagyu/illustrator"
+)
+
+var (
+	input    = flag.String("i", "", "-i <input ai file path>")
+	ps       = flag.String("ps", "", "-ps <private data file path>, e.g. the output of ai2ps")
+	output   = flag.String("o", "", "-o <output file path>")
+	encoding = flag.String("encoding", "zlib", "-encoding <zlib|zstd|none>")
+)
+
+func main() {
+	flag.Parse()
+
+	if len(*output) == 0 {
+		*output = strings.TrimSuffix(*input, ".ai") + "-new.ai"
+	}
+
+	f, err := illustrator.Open(*input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.PDF == nil {
+		log.Fatal("the input is not a pdf compatible ai file")
+	}
+
+	data, err := os.Open(*ps)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer data.Close()
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if err := f.PDF.WritePrivateData(file, data, illustrator.SetEncoding(*encoding)); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,110 @@
+package illustrator
+
+import "math"
+
+type ColorSpace int8
+
+const (
+	ColorGray ColorSpace = iota
+	ColorRGB
+	ColorCMYK
+	ColorSpot // named custom color
+)
+
+func (s ColorSpace) String() string {
+	switch s {
+	case ColorGray:
+		return "gray"
+	case ColorRGB:
+		return "rgb"
+	case ColorCMYK:
+		return "cmyk"
+	case ColorSpot:
+		return "spot"
+	}
+	return "unknown"
+}
+
+// Color is a resolved paint color, components are between 0-1
+type Color struct {
+	Space ColorSpace
+	Gray  float64 // 0-black; 1-white
+	RGB   [3]float64
+	CMYK  [4]float64
+
+	// spot colors, RGB or CMYK hold the tinted alternate color
+	Name string
+	Tint float64    // 0-full ink; 1-no ink
+	Alt  ColorSpace // ColorRGB or ColorCMYK
+}
+
+func GrayColor(gray float64) Color {
+	return Color{Space: ColorGray, Gray: gray}
+}
+
+func RGBColor(r, g, b float64) Color {
+	return Color{Space: ColorRGB, RGB: [3]float64{r, g, b}}
+}
+
+func CMYKColor(c, m, y, k float64) Color {
+	return Color{Space: ColorCMYK, CMYK: [4]float64{c, m, y, k}}
+}
+
+// ToRGB converts the color to 8-bit rgb
+func (c Color) ToRGB() [3]uint8 {
+	space := c.Space
+	if space == ColorSpot {
+		space = c.Alt
+	}
+
+	switch space {
+	case ColorGray:
+		v := uint8(math.Round(c.Gray * 255))
+		return [3]uint8{v, v, v}
+	case ColorRGB:
+		return [3]uint8{
+			uint8(math.Round(c.RGB[0] * 255)),
+			uint8(math.Round(c.RGB[1] * 255)),
+			uint8(math.Round(c.RGB[2] * 255)),
+		}
+	}
+
+	cyan, m := c.CMYK[0], c.CMYK[1]
+	y, k := c.CMYK[2], c.CMYK[3]
+	return [3]uint8{
+		uint8(math.Round((1 - cyan) * (1 - k) * 255)),
+		uint8(math.Round((1 - m) * (1 - k) * 255)),
+		uint8(math.Round((1 - y) * (1 - k) * 255)),
+	}
+}
+
+// ToCMYK converts the color to cmyk, rgb is converted naively
+func (c Color) ToCMYK() [4]float64 {
+	space := c.Space
+	if space == ColorSpot {
+		space = c.Alt
+	}
+
+	switch space {
+	case ColorGray:
+		return [4]float64{0, 0, 0, 1 - c.Gray}
+	case ColorRGB:
+		k := 1 - math.Max(c.RGB[0], math.Max(c.RGB[1], c.RGB[2]))
+		if k >= 1 {
+			return [4]float64{0, 0, 0, 1}
+		}
+		return [4]float64{
+			(1 - c.RGB[0] - k) / (1 - k),
+			(1 - c.RGB[1] - k) / (1 - k),
+			(1 - c.RGB[2] - k) / (1 - k),
+			k,
+		}
+	}
+	return c.CMYK
+}
+
+// IsRGB reports whether the color is given in rgb, including spot colors
+// with an rgb alternate
+func (c Color) IsRGB() bool {
+	return c.Space == ColorRGB || (c.Space == ColorSpot && c.Alt == ColorRGB)
+}
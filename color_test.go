@@ -0,0 +1,57 @@
+package illustrator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorOperators(t *testing.T) {
+	const layer = "%%EndComments\n%AI5_BeginLayer\n1 1 1 1 0 0 -1 79 128 255 Lb\n"
+	tests := []struct {
+		src   string
+		op    PathOp
+		space ColorSpace // the alternate space of spot colors
+		rgb   [3]uint8
+	}{
+		{"1 0.5 0 Xa", AI_Fill, ColorRGB, [3]uint8{255, 128, 0}},
+		{"0 0 1 XA", AI_Stroke, ColorRGB, [3]uint8{0, 0, 255}},
+		{"0 1 0 0 1 0 0 Xa", AI_Fill, ColorRGB, [3]uint8{255, 0, 0}},
+		{"0 0.5 1 (spot) 0 1 Xx", AI_Fill, ColorRGB, [3]uint8{0, 128, 255}},
+		{"0 0 1 (spot) 0 1 XX", AI_Stroke, ColorRGB, [3]uint8{0, 0, 255}},
+		{"0 1 0 0 (spot) 0 0 Xx", AI_Fill, ColorCMYK, [3]uint8{255, 0, 255}},
+		{"1 0 0 0 (spot) 0 0 XX", AI_Stroke, ColorCMYK, [3]uint8{0, 255, 255}},
+		{"0 0 0 (spot) 0.5 1 Xx", AI_Fill, ColorRGB, [3]uint8{128, 128, 128}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			r, err := NewAIReader(strings.NewReader(layer + tt.src + "\nLB\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := NewRecordingDrawer(nil)
+			if err := r.DrawV2(rec); err != nil {
+				t.Fatal(err)
+			}
+
+			var found bool
+			for _, call := range rec.Calls {
+				if call.Method != "SetColor" {
+					continue
+				}
+				found = true
+				op, c := call.Args[0].(PathOp), call.Args[1].(Color)
+				space := c.Space
+				if space == ColorSpot {
+					space = c.Alt
+				}
+				if op != tt.op || space != tt.space || c.ToRGB() != tt.rgb {
+					t.Errorf("got %v %v %v, want %v %v %v", op, space, c.ToRGB(), tt.op, tt.space, tt.rgb)
+				}
+			}
+			if !found {
+				t.Errorf("no SetColor in %v", rec.Calls)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/zlib"
 	"io"
+	"sync"
 
 	"github.com/klauspost/compress/zstd"
 )
@@ -12,6 +13,93 @@ type CompressHandle interface {
 	Decompress() ([]byte, error)
 
 	Write(stream []byte) (n int, err error)
+
+	// Compress compresses data, without the header of the encoding which
+	// the writer takes from the registered Encoding
+	Compress(data io.Reader) ([]byte, error)
+}
+
+// StreamHandle decompresses a stream without reading it into memory
+type StreamHandle interface {
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// default size of the AIPrivateDataN streams written
+const privateChunkSize = 64 * 1024
+
+// Encoding is a registered encoding of the private data
+type Encoding struct {
+	Name   string
+	Header string // prefix of the first AIPrivateDataN stream
+	New    func() CompressHandle
+}
+
+var (
+	encodingMu sync.RWMutex
+	encodings  []Encoding
+)
+
+func init() {
+	RegisterEncoding("zlib", "%AI12_CompressedData", func() CompressHandle { return &ZlibCompress{} })
+	RegisterEncoding("zstd", "%AI24_ZStandard_Data", func() CompressHandle { return &ZStdCompress{} })
+	RegisterEncoding("none", "", func() CompressHandle { return &PlainData{} })
+}
+
+// RegisterEncoding registers an encoding of the private data, recognized
+// by the header of the first stream, the header is stripped before the
+// data is decompressed. An empty header is never recognized, the encoding
+// is only used for writing. An encoding of the same name is replaced.
+func RegisterEncoding(name, header string, fn func() CompressHandle) {
+	encodingMu.Lock()
+	defer encodingMu.Unlock()
+
+	for i := range encodings {
+		if encodings[i].Name == name {
+			encodings[i] = Encoding{Name: name, Header: header, New: fn}
+			return
+		}
+	}
+	encodings = append(encodings, Encoding{Name: name, Header: header, New: fn})
+}
+
+// LookupEncoding returns the encoding registered as name
+func LookupEncoding(name string) (Encoding, bool) {
+	encodingMu.RLock()
+	defer encodingMu.RUnlock()
+
+	for _, e := range encodings {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Encoding{}, false
+}
+
+// matchEncoding returns the encoding of the header of data
+func matchEncoding(data []byte) (Encoding, bool) {
+	encodingMu.RLock()
+	defer encodingMu.RUnlock()
+
+	for _, e := range encodings {
+		if len(e.Header) > 0 && bytes.HasPrefix(data, []byte(e.Header)) {
+			return e, true
+		}
+	}
+	return Encoding{}, false
+}
+
+// splitChunks splits data into streams of size bytes
+func splitChunks(data []byte, size int) [][]byte {
+	var chunks [][]byte
+	for len(data) > 0 {
+		n := size
+		if n > len(data) {
+			n = len(data)
+		}
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return chunks
 }
 
 type ZlibCompress struct {
@@ -37,6 +125,23 @@ func (c *ZlibCompress) Decompress() ([]byte, error) {
 	return io.ReadAll(rd)
 }
 
+func (c *ZlibCompress) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+func (c *ZlibCompress) Compress(data io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := io.Copy(zw, data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 // ZStdCompress
 type ZStdCompress struct {
 	buf *bytes.Buffer
@@ -60,3 +165,58 @@ func (c *ZStdCompress) Decompress() ([]byte, error) {
 
 	return rd.DecodeAll(c.buf.Bytes(), nil)
 }
+
+func (c *ZStdCompress) NewReader(r io.Reader) (io.ReadCloser, error) {
+	rd, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+	if err != nil {
+		return nil, err
+	}
+
+	return rd.IOReadCloser(), nil
+}
+
+func (c *ZStdCompress) Compress(data io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(zw, data); err != nil {
+		zw.Close()
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// PlainData is the uncompressed private data of older files
+type PlainData struct {
+	buf *bytes.Buffer
+}
+
+func (c *PlainData) Write(stream []byte) (int, error) {
+	if c.buf == nil {
+		c.buf = bytes.NewBuffer(stream)
+		return len(stream), nil
+	}
+
+	return c.buf.Write(stream)
+}
+
+func (c *PlainData) Decompress() ([]byte, error) {
+	if c.buf == nil {
+		return nil, nil
+	}
+	return c.buf.Bytes(), nil
+}
+
+func (c *PlainData) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+func (c *PlainData) Compress(data io.Reader) ([]byte, error) {
+	return io.ReadAll(data)
+}
@@ -0,0 +1,118 @@
+package illustrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrLimitExceeded = errors.New("illustrator: limit exceeded")
+
+// Limits guards the reader against oversized or malicious files,
+// zero values are unlimited
+type Limits struct {
+	MaxLines       int   // lines of private data
+	MaxRasterBytes int64 // raster data in total
+	MaxNodes       int   // painted paths, groups, rasters, meshes and placed art, and the nodes of a mesh
+}
+
+// SetLimits sets the limits checked while drawing
+func (r *AIReader) SetLimits(l Limits) {
+	r.limits = l
+}
+
+// how many tokens are read between two checks of the context
+const ctxCheckInterval = 1024
+
+// DrawContext reads the artwork into drawer, it stops with ctx.Err()
+// once ctx is done
+func (r *AIReader) DrawContext(ctx context.Context, drawer DrawerV2) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.ctx = ctx
+	defer func() { r.ctx = nil }()
+	return r.DrawV2(drawer)
+}
+
+// checkToken checks the context and the line limit, called for each token
+func (r *AIReader) checkToken() {
+	r.tokens++
+	if r.ctx != nil && r.tokens%ctxCheckInterval == 0 {
+		if err := r.ctx.Err(); err != nil {
+			r.fail(err)
+		}
+	}
+	r.checkLines()
+}
+
+// checkLines checks the line limit, raster data included
+func (r *AIReader) checkLines() {
+	if max := r.limits.MaxLines; max > 0 && r.lexer.Line() > max {
+		r.fail(fmt.Errorf("%w: more than %d lines", ErrLimitExceeded, max))
+	}
+}
+
+// checkRaster checks the context and the raster limit, n bytes of raster
+// data have been read
+func (r *AIReader) checkRaster(n int) {
+	r.rasterBytes += int64(n)
+	if max := r.limits.MaxRasterBytes; max > 0 && r.rasterBytes > max {
+		r.fail(fmt.Errorf("%w: more than %d bytes of raster data", ErrLimitExceeded, max))
+	}
+
+	if r.ctx != nil && r.rasterBytes%(ctxCheckInterval*64) == 0 {
+		if err := r.ctx.Err(); err != nil {
+			r.fail(err)
+		}
+	}
+	r.checkLines()
+}
+
+// nodeCounter counts the nodes drawn, for Limits.MaxNodes, the nodes past
+// the limit are dropped and the reader stops at the next token
+type nodeCounter struct {
+	DrawerV2
+	r     *AIReader
+	count int
+	max   int
+}
+
+func (c *nodeCounter) add() bool {
+	if c.count++; c.count > c.max {
+		c.r.fail(fmt.Errorf("%w: more than %d nodes", ErrLimitExceeded, c.max))
+		return false
+	}
+	return true
+}
+
+func (c *nodeCounter) PathRender(op PathOp, gs *GraphicsState) {
+	if op == 0 || c.add() {
+		c.DrawerV2.PathRender(op, gs)
+	}
+}
+
+func (c *nodeCounter) Group() {
+	if c.add() {
+		c.DrawerV2.Group()
+	}
+}
+
+func (c *nodeCounter) SetRaster(obj *Raster) {
+	if c.add() {
+		c.DrawerV2.SetRaster(obj)
+	}
+}
+
+func (c *nodeCounter) PlacedArt(obj *PlacedArt) {
+	if c.add() {
+		c.DrawerV2.PlacedArt(obj)
+	}
+}
+
+func (c *nodeCounter) Mesh(m *Mesh) {
+	if c.add() {
+		c.DrawerV2.Mesh(m)
+	}
+}
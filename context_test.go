@@ -0,0 +1,66 @@
+package illustrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// rasterSource returns a layer with a raster of n lines of data
+func rasterSource(n int) string {
+	var w strings.Builder
+	w.WriteString("%%EndComments\n%AI5_BeginLayer\n1 1 1 1 0 0 -1 79 128 255 Lb\n")
+	w.WriteString("%AI5_BeginRaster\n[ 1 0 0 1 0 0 ] 0 0 2 2 2 2 8 3 0 0 0 0\nXI\n")
+	w.WriteString("%%BeginData: binary\n")
+	for i := 0; i < n; i++ {
+		w.WriteString("abcdefgh\n")
+	}
+	w.WriteString("%%EndData\n%AI5_EndRaster\n0 0 m 1 1 L S\nLB\n")
+	return w.String()
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		limits Limits
+		err    error
+	}{
+		{"lines", rasterSource(0), Limits{MaxLines: 5}, ErrLimitExceeded},
+		{"raster lines", rasterSource(100), Limits{MaxLines: 50}, ErrLimitExceeded},
+		{"raster bytes", rasterSource(100), Limits{MaxRasterBytes: 100}, ErrLimitExceeded},
+		{"nodes", rasterSource(1), Limits{MaxNodes: 1}, ErrLimitExceeded},
+		{"mesh nodes", "%%EndComments\n%AI5_BeginLayer\n1 1 1 1 0 0 -1 79 128 255 Lb\n%AI8_BeginMesh\n10 10 Xd\n%AI8_EndMesh\n", Limits{MaxNodes: 100}, ErrLimitExceeded},
+		{"under the limits", rasterSource(100), Limits{MaxLines: 200, MaxRasterBytes: 1000, MaxNodes: 2}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewAIReader(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.SetLimits(tt.limits)
+			if err := r.DrawV2(BaseDrawer{}); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDrawContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r, _ := NewAIReader(strings.NewReader(rasterSource(1)))
+	if err := r.DrawContext(ctx, BaseDrawer{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+
+	// malformed data is an error, not a panic
+	r, _ = NewAIReader(strings.NewReader(rasterSource(1) + "(unterminated"))
+	if err := r.DrawContext(context.Background(), BaseDrawer{}); err == nil {
+		t.Error("got no error for an unterminated string")
+	}
+}
@@ -0,0 +1,170 @@
+package illustrator
+
+import "strings"
+
+// Dict is an illustrator serialized dictionary, e.g.
+//
+//	/Dictionary : /NotRecorded ,
+//	(Drop Shadow) /String (Name) ,
+//	0.75 /Real (opac) ,
+//	;
+//
+// values are float64, int, bool, string, Dict or []interface{}
+type Dict map[string]interface{}
+
+func (d Dict) String(key string) string {
+	v, _ := d[key].(string)
+	return v
+}
+
+func (d Dict) Float(key string) float64 {
+	switch v := d[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+func (d Dict) Int(key string) int {
+	switch v := d[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func (d Dict) Bool(key string) bool {
+	switch v := d[key].(type) {
+	case bool:
+		return v
+	case int:
+		return v != 0
+	}
+	return false
+}
+
+func (d Dict) Dict(key string) Dict {
+	v, _ := d[key].(Dict)
+	return v
+}
+
+func (d Dict) Array(key string) []interface{} {
+	v, _ := d[key].([]interface{})
+	return v
+}
+
+type dictFrame struct {
+	dict  Dict
+	array []interface{}
+}
+
+// dictParser builds dictionaries from the tokens of a serialized dictionary
+type dictParser struct {
+	frames   []*dictFrame
+	operands []interface{}
+
+	roots []Dict // top level dictionaries
+}
+
+func (p *dictParser) push(tok string) {
+	switch tok {
+	case ":": // /Dictionary : or /Array :
+		var kind string
+		if l := len(p.operands); l > 0 {
+			kind, _ = p.operands[l-1].(string)
+		}
+		p.operands = p.operands[:0]
+
+		frame := &dictFrame{}
+		if kind == "/Array" {
+			frame.array = make([]interface{}, 0)
+		} else {
+			frame.dict = make(Dict)
+		}
+		p.frames = append(p.frames, frame)
+	case ";":
+		l := len(p.frames)
+		if l == 0 {
+			return
+		}
+
+		frame := p.frames[l-1]
+		p.frames = p.frames[:l-1]
+		p.operands = p.operands[:0]
+		if frame.dict != nil {
+			if l == 1 {
+				p.roots = append(p.roots, frame.dict)
+			}
+			p.operands = append(p.operands, frame.dict)
+		} else {
+			p.operands = append(p.operands, frame.array)
+		}
+	case ",":
+		p.entry()
+	default:
+		if len(p.frames) == 0 && len(p.operands) > 0 {
+			// outside of a dictionary only the kind before ':' matters
+			p.operands = p.operands[:0]
+		}
+		p.operands = append(p.operands, tok)
+	}
+}
+
+func (p *dictParser) entry() {
+	operands := p.operands
+	p.operands = p.operands[:0]
+
+	l := len(p.frames)
+	if l == 0 || len(operands) == 0 {
+		return
+	}
+
+	frame := p.frames[l-1]
+	if frame.dict != nil {
+		// value [/Type] (key) ,
+		if len(operands) < 2 {
+			return // flags such as /NotRecorded
+		}
+
+		key, _ := operands[len(operands)-1].(string)
+		frame.dict[toString(key)] = dictValue(operands[:len(operands)-1])
+	} else {
+		// value [/Type] ,
+		frame.array = append(frame.array, dictValue(operands))
+	}
+}
+
+func dictValue(operands []interface{}) interface{} {
+	l := len(operands)
+	if l == 0 {
+		return nil
+	}
+
+	kind, _ := operands[l-1].(string)
+	if l == 1 || !strings.HasPrefix(kind, "/") {
+		if s, ok := operands[l-1].(string); ok {
+			return toString(s)
+		}
+		return operands[l-1]
+	}
+
+	raw, ok := operands[l-2].(string)
+	if !ok {
+		return operands[l-2]
+	}
+
+	switch kind {
+	case "/Real":
+		return toFloat(raw)
+	case "/Int":
+		return toInt(raw)
+	case "/Bool":
+		return raw == "1" || raw == "true"
+	default: // /String, /UnicodeString, /Name
+		return toString(strings.TrimPrefix(raw, "/"))
+	}
+}
@@ -0,0 +1,456 @@
+package illustrator
+
+import (
+	"strconv"
+)
+
+type NodeType int8
+
+const (
+	NodeLayer NodeType = iota
+	NodeGroup
+	NodePath
+	NodeCompoundPath
+	NodeRaster
+	NodeMesh
+	NodePlacedArt
+)
+
+func (t NodeType) String() string {
+	switch t {
+	case NodeLayer:
+		return "layer"
+	case NodeGroup:
+		return "group"
+	case NodePath:
+		return "path"
+	case NodeCompoundPath:
+		return "compoundpath"
+	case NodeRaster:
+		return "raster"
+	case NodeMesh:
+		return "mesh"
+	case NodePlacedArt:
+		return "placedart"
+	}
+	return "node" + strconv.Itoa(int(t))
+}
+
+// Node is an element of the document tree
+type Node interface {
+	Type() NodeType
+}
+
+// Document is an in-memory model of the artwork
+type Document struct {
+	Header    AIHeader
+	Layers    []*Layer
+	Gradients []Gradient
+	Guides    []Guide
+	Resources *Resources
+}
+
+// Layer returns the top level layer with the given name
+func (doc *Document) Layer(name string) *Layer {
+	for _, l := range doc.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Walk visits the nodes of the document in depth-first order, the
+// children of a node are skipped when fn returns false
+func (doc *Document) Walk(fn func(n Node, depth int) bool) {
+	for _, l := range doc.Layers {
+		walk(l, 0, fn)
+	}
+}
+
+func walk(n Node, depth int, fn func(Node, int) bool) {
+	if !fn(n, depth) {
+		return
+	}
+
+	var childs []Node
+	switch node := n.(type) {
+	case *Layer:
+		childs = node.Children
+	case *Group:
+		childs = node.Children
+	}
+
+	for _, c := range childs {
+		walk(c, depth+1, fn)
+	}
+}
+
+// Find returns the nodes accepted by filter
+func (doc *Document) Find(filter func(Node) bool) []Node {
+	var nodes []Node
+	doc.Walk(func(n Node, _ int) bool {
+		if filter(n) {
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+	return nodes
+}
+
+type Layer struct {
+	AILayer
+	Children []Node
+}
+
+func (l *Layer) Type() NodeType { return NodeLayer }
+
+type Group struct {
+	Clip      bool    // clip group
+	Clips     []*Path // clipping paths, the union of them
+	Effects   []Effect
+	Transform *Matrix // transform of the children, a group is made for each concat
+	Children  []Node
+}
+
+func (g *Group) Type() NodeType { return NodeGroup }
+
+type SegmentOp byte
+
+const (
+	SegMoveto  SegmentOp = 'M'
+	SegLineto  SegmentOp = 'L'
+	SegCurveto SegmentOp = 'C'
+	SegClose   SegmentOp = 'Z'
+)
+
+type Segment struct {
+	Op     SegmentOp
+	Points [3][2]float64 // Moveto and Lineto use the first point, Curveto all of them
+}
+
+type Path struct {
+	Segments []Segment
+	Op       PathOp // fill and/or stroke
+	Style    GraphicsState
+	Gradient *Gradient // gradient fill
+	Effects  []Effect
+}
+
+func (p *Path) Type() NodeType { return NodePath }
+
+type CompoundPath struct {
+	Paths    []*Path
+	Op       PathOp
+	Style    GraphicsState
+	Gradient *Gradient // gradient fill
+	Effects  []Effect
+}
+
+func (p *CompoundPath) Type() NodeType { return NodeCompoundPath }
+
+func (r *Raster) Type() NodeType    { return NodeRaster }
+func (m *Mesh) Type() NodeType      { return NodeMesh }
+func (p *PlacedArt) Type() NodeType { return NodePlacedArt }
+
+type container interface {
+	add(n Node)
+}
+
+func (l *Layer) add(n Node) { l.Children = append(l.Children, n) }
+func (g *Group) add(n Node) { g.Children = append(g.Children, n) }
+
+// DocumentBuilder is a DrawerV2 building a Document
+type DocumentBuilder struct {
+	doc *Document
+
+	stack    []container // open layers and groups
+	effects  []Effect    // effects of the next group or path
+	path     *Path
+	clip     bool // the current path is a clipping path
+	compound *CompoundPath
+	clipping bool      // the current compound path is a clipping path
+	gradient *Gradient // gradient of the next painted path or compound path
+	point    [2]float64
+}
+
+func NewDocumentBuilder() *DocumentBuilder {
+	return &DocumentBuilder{
+		doc: &Document{},
+	}
+}
+
+func (b *DocumentBuilder) Document() *Document {
+	return b.doc
+}
+
+// Document reads the artwork into a Document
+func (r *AIReader) Document() (*Document, error) {
+	builder := NewDocumentBuilder()
+	if err := r.DrawV2(builder); err != nil {
+		return nil, err
+	}
+
+	doc := builder.Document()
+	doc.Resources = r.Resources()
+	return doc, nil
+}
+
+func (b *DocumentBuilder) top() container {
+	if l := len(b.stack); l > 0 {
+		return b.stack[l-1]
+	}
+
+	// art outside of any layer
+	layer := &Layer{AILayer: AILayer{Name: "Layer", Visible: true, Preview: true, Printing: true}}
+	b.doc.Layers = append(b.doc.Layers, layer)
+	b.stack = append(b.stack, layer)
+	return layer
+}
+
+func (b *DocumentBuilder) group() *Group {
+	for i := len(b.stack) - 1; i >= 0; i-- {
+		if g, ok := b.stack[i].(*Group); ok {
+			return g
+		}
+	}
+	return nil
+}
+
+func (b *DocumentBuilder) takeEffects() []Effect {
+	effects := b.effects
+	b.effects = nil
+	return effects
+}
+
+func (b *DocumentBuilder) SetHeader(header *AIHeader) {
+	b.doc.Header = *header
+}
+
+func (b *DocumentBuilder) BeginLayer(l *AILayer) {
+	layer := &Layer{AILayer: *l}
+	if len(b.stack) == 0 {
+		b.doc.Layers = append(b.doc.Layers, layer)
+	} else {
+		b.top().add(layer) // sublayer
+	}
+	b.stack = append(b.stack, layer)
+}
+
+func (b *DocumentBuilder) SetLayerName(name string) {
+	for i := len(b.stack) - 1; i >= 0; i-- {
+		if l, ok := b.stack[i].(*Layer); ok {
+			l.Name = toString(name)
+			return
+		}
+	}
+}
+
+func (b *DocumentBuilder) EndLayer() {
+	for i := len(b.stack) - 1; i >= 0; i-- {
+		if _, ok := b.stack[i].(*Layer); ok {
+			b.stack = b.stack[:i]
+			return
+		}
+	}
+}
+
+func (b *DocumentBuilder) Group() {
+	g := &Group{Effects: b.takeEffects()}
+	b.top().add(g)
+	b.stack = append(b.stack, g)
+}
+
+// EndGroup closes the innermost group of Group, transforms still open in
+// it are closed with it
+func (b *DocumentBuilder) EndGroup() {
+	for i := len(b.stack) - 1; i >= 0; i-- {
+		g, ok := b.stack[i].(*Group)
+		if !ok {
+			return // no group open in the layer
+		}
+		if g.Transform == nil {
+			b.stack = b.stack[:i]
+			return
+		}
+	}
+}
+
+func (b *DocumentBuilder) SetGroupAttr() {}
+
+func (b *DocumentBuilder) segment(op SegmentOp, points ...float64) {
+	if b.path == nil {
+		b.path = &Path{}
+	}
+
+	seg := Segment{Op: op}
+	for i := 0; i+1 < len(points); i += 2 {
+		seg.Points[i/2] = [2]float64{points[i], points[i+1]}
+	}
+	b.path.Segments = append(b.path.Segments, seg)
+
+	if l := len(points); l >= 2 {
+		b.point = [2]float64{points[l-2], points[l-1]}
+	}
+}
+
+func (b *DocumentBuilder) ClosePath() {
+	if b.path != nil {
+		b.segment(SegClose)
+	}
+}
+
+func (b *DocumentBuilder) PathRender(op PathOp, gs *GraphicsState) {
+	path := b.path
+	b.path, b.clip = nil, false
+	if path == nil {
+		return
+	}
+
+	if b.compound != nil {
+		// the style of a compound path is the style of its last path, the
+		// gradient is kept for EndCompoundPath
+		b.compound.Op = op
+		b.compound.Style = gs.Clone()
+		b.compound.Paths = append(b.compound.Paths, path)
+		return
+	}
+
+	if op == 0 {
+		return // no fill no stroke
+	}
+
+	path.Op = op
+	path.Style = gs.Clone()
+	path.Effects = b.takeEffects()
+	path.Gradient = b.gradient
+	b.gradient = nil
+	b.top().add(path)
+}
+
+func (b *DocumentBuilder) Moveto(x, y float64) {
+	if b.path != nil && b.compound == nil && !b.clip {
+		b.path = nil // unpainted path
+	}
+	b.segment(SegMoveto, x, y)
+}
+
+func (b *DocumentBuilder) Lineto(x, y float64) {
+	b.segment(SegLineto, x, y)
+}
+
+func (b *DocumentBuilder) Curveto1(x0, y0, x1, y1 float64) {
+	b.segment(SegCurveto, x0, y0, x1, y1, x1, y1)
+}
+
+func (b *DocumentBuilder) Curveto2(x1, y1, x2, y2 float64) {
+	b.segment(SegCurveto, b.point[0], b.point[1], x1, y1, x2, y2)
+}
+
+func (b *DocumentBuilder) Curveto(x0, y0, x1, y1, x2, y2 float64) {
+	b.segment(SegCurveto, x0, y0, x1, y1, x2, y2)
+}
+
+func (b *DocumentBuilder) Guide(g *Guide) {
+	b.doc.Guides = append(b.doc.Guides, *g)
+}
+
+func (b *DocumentBuilder) ClipPath() {
+	b.clip = b.path != nil
+}
+
+func (b *DocumentBuilder) ApplyClip() {
+	g := b.group()
+	if g == nil {
+		return
+	}
+
+	g.Clip = true
+	if b.compound != nil {
+		b.clipping = true
+		return
+	}
+
+	if b.path != nil {
+		g.Clips = append(g.Clips, b.path)
+		b.path, b.clip = nil, false
+	}
+}
+
+func (b *DocumentBuilder) CompoundPath() {
+	b.path = nil
+	b.compound = &CompoundPath{}
+	b.clipping = false
+}
+
+func (b *DocumentBuilder) EndCompoundPath() {
+	compound, gradient := b.compound, b.gradient
+	b.compound, b.gradient = nil, nil
+	if compound == nil || len(compound.Paths) == 0 {
+		return
+	}
+
+	if b.clipping {
+		if g := b.group(); g != nil {
+			g.Clips = append(g.Clips, compound.Paths...)
+		}
+		return
+	}
+
+	if compound.Op == 0 {
+		return // no fill no stroke
+	}
+
+	compound.Effects = b.takeEffects()
+	compound.Gradient = gradient
+	b.top().add(compound)
+}
+
+// the style of paths is taken from the graphics state of PathRender
+func (b *DocumentBuilder) SetColor(op PathOp, c Color)           {}
+func (b *DocumentBuilder) SetOpacity(opacity float64)            {}
+func (b *DocumentBuilder) SetDash(dash []float64, phase float64) {}
+func (b *DocumentBuilder) SetFlat(flatness float64)              {}
+func (b *DocumentBuilder) SetLineCap(v LineCap)                  {}
+func (b *DocumentBuilder) SetLineJoin(v LineJoin)                {}
+func (b *DocumentBuilder) SetLineWidth(v float64)                {}
+func (b *DocumentBuilder) SetMiterLimit(v float64)               {}
+
+func (b *DocumentBuilder) DefGradient(g *Gradient) {
+	b.doc.Gradients = append(b.doc.Gradients, *g)
+}
+
+func (b *DocumentBuilder) SetGradient(g *Gradient) {
+	gradient := *g
+	b.gradient = &gradient
+}
+
+func (b *DocumentBuilder) SetRaster(obj *Raster) {
+	b.top().add(obj)
+}
+
+func (b *DocumentBuilder) PlacedArt(obj *PlacedArt) {
+	b.top().add(obj)
+}
+
+func (b *DocumentBuilder) Mesh(m *Mesh) {
+	b.top().add(m)
+}
+
+func (b *DocumentBuilder) PushTransform(m Matrix) {
+	g := &Group{Transform: &m}
+	b.top().add(g)
+	b.stack = append(b.stack, g)
+}
+
+func (b *DocumentBuilder) PopTransform() {
+	if l := len(b.stack); l > 0 {
+		if g, ok := b.stack[l-1].(*Group); ok && g.Transform != nil {
+			b.stack = b.stack[:l-1]
+		}
+	}
+}
+
+func (b *DocumentBuilder) SetEffects(effects []Effect) {
+	b.effects = effects
+}
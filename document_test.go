@@ -0,0 +1,159 @@
+package illustrator
+
+import (
+	"strings"
+	"testing"
+)
+
+func buildDocument(t *testing.T, src string) *Document {
+	t.Helper()
+
+	r, err := NewAIReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := r.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+const gradientSource = `%%EndComments
+%AI5_BeginLayer
+1 1 1 1 0 0 -1 79 128 255 Lb
+(Layer 1) Ln
+0 0 m 10 0 L 10 10 L f
+0 0 m 100 0 L 100 100 L
+Bb
+2 (fill) 0 0 0 100 1 0 0 1 0 0 Bg
+f
+1 BB
+0 0 m 50 50 L
+Bb
+2 (stroke) 0 0 0 100 1 0 0 1 0 0 Bg
+1 BB
+*u
+0 0 m 30 0 L 30 30 L
+Bb
+2 (compound) 0 0 0 100 1 0 0 1 0 0 Bg
+f
+0 BB
+5 5 m 25 5 L 25 25 L f
+*U
+0 0 m 1 1 L S
+LB
+`
+
+func TestDocumentGradient(t *testing.T) {
+	doc := buildDocument(t, gradientSource)
+	nodes := doc.Layers[0].Children
+	if len(nodes) != 5 {
+		t.Fatalf("got %d nodes, want 5", len(nodes))
+	}
+
+	for i, flag := range []int8{0, 2, 2, 2, 0} {
+		g := nodeGradient(nodes[i])
+		if (g != nil) != (flag != 0) {
+			t.Errorf("node %d: gradient %v, want one: %v", i, g, flag != 0)
+			continue
+		}
+		if g != nil && g.Flag != flag {
+			t.Errorf("node %d: flag %d, want %d", i, g.Flag, flag)
+		}
+	}
+	if _, ok := nodes[3].(*CompoundPath); !ok {
+		t.Errorf("node 3 is %T, want a compound path", nodes[3])
+	}
+}
+
+func TestDocumentEndGroup(t *testing.T) {
+	const src = `%%EndComments
+%AI5_BeginLayer
+1 1 1 1 0 0 -1 79 128 255 Lb
+(Layer 1) Ln
+u
+[ 1 0 0 1 10 20 ] concat
+0 0 m 10 0 L 10 10 L f
+U
+0 0 m 1 1 L S
+LB
+`
+	doc := buildDocument(t, src)
+	nodes := doc.Layers[0].Children
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes in the layer, want the group and the path", len(nodes))
+	}
+
+	group, ok := nodes[0].(*Group)
+	if !ok || group.Transform != nil || len(group.Children) != 1 {
+		t.Fatalf("got %#v, want a group holding the transform", nodes[0])
+	}
+	if tr, ok := group.Children[0].(*Group); !ok || tr.Transform == nil {
+		t.Errorf("got %#v, want a transform group", group.Children[0])
+	}
+
+	// transforms left open are closed by EndGroup
+	b := NewDocumentBuilder()
+	b.BeginLayer(&AILayer{Name: "Layer 1"})
+	b.Group()
+	b.PushTransform(Translate(1, 2))
+	b.EndGroup()
+	b.Moveto(0, 0)
+	b.Lineto(1, 1)
+	b.PathRender(AI_Stroke, &GraphicsState{})
+	b.EndLayer()
+
+	layer := b.Document().Layers[0]
+	if len(layer.Children) != 2 {
+		t.Fatalf("got %d nodes in the layer, want the group and the path", len(layer.Children))
+	}
+	if _, ok := layer.Children[1].(*Path); !ok {
+		t.Errorf("got %T, want the path after the group", layer.Children[1])
+	}
+}
+
+// nodeGradient returns the gradient fill of a path or compound path
+func nodeGradient(n Node) *Gradient {
+	switch node := n.(type) {
+	case *Path:
+		return node.Gradient
+	case *CompoundPath:
+		return node.Gradient
+	}
+	return nil
+}
+
+func TestFilterGradient(t *testing.T) {
+	tests := []struct {
+		name string
+		skip NodeType
+		want []bool // the kept nodes have a gradient
+	}{
+		{"compound paths kept", NodePath, []bool{true}},
+		{"paths kept", NodeCompoundPath, []bool{false, true, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewAIReader(strings.NewReader(gradientSource))
+			if err != nil {
+				t.Fatal(err)
+			}
+			builder := NewDocumentBuilder()
+			if err := r.DrawV2(NewFilterDrawer(builder, SetFilterSkipTypes(tt.skip))); err != nil {
+				t.Fatal(err)
+			}
+
+			nodes := builder.Document().Layers[0].Children
+			if len(nodes) != len(tt.want) {
+				t.Fatalf("got %d nodes, want %d", len(nodes), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := nodeGradient(nodes[i]) != nil; got != want {
+					t.Errorf("node %d %T: gradient %v, want %v", i, nodes[i], got, want)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package illustrator
 
-type Drawer interface {
+import "strconv"
+
+// ArtDrawer holds the methods shared by Drawer and DrawerV2
+type ArtDrawer interface {
 	SetHeader(*AIHeader)
 
 	// Layer
@@ -15,7 +18,6 @@ type Drawer interface {
 
 	// path
 	ClosePath()
-	PathRender(PathOp)
 	Moveto(x, y float64)
 	Lineto(x, y float64)
 	Curveto1(x0, y0, x1, y1 float64)
@@ -30,6 +32,21 @@ type Drawer interface {
 	CompoundPath()
 	EndCompoundPath()
 
+	// gradient
+	DefGradient(g *Gradient) //
+	SetGradient(g *Gradient) // fill of the next painted path or compound path
+
+	// raster
+	SetRaster(obj *Raster)
+}
+
+// Drawer receives raw operands of the style operators, it is kept for
+// existing implementations and adapted by NewDrawerV2
+type Drawer interface {
+	ArtDrawer
+
+	PathRender(PathOp)
+
 	// set color
 	SetRGB(PathOp, [3]uint8)
 	SetCMYK(PathOp, [4]float64)
@@ -42,11 +59,133 @@ type Drawer interface {
 	SetLineJoin(v string)
 	SetLineWidth(v string)
 	SetMiterLimit(v string)
+}
 
-	// gradient
-	DefGradient(g *Gradient) //
-	SetGradient(g *Gradient)
+// Transformer receives the matrices of concat, a transform applies to
+// the following art until it is popped
+type Transformer interface {
+	PushTransform(m Matrix)
+	PopTransform()
+}
 
-	// raster
-	SetRaster(obj *Raster)
+// DrawerV2 receives typed values of the style operators and the art
+// which Drawer does not know
+type DrawerV2 interface {
+	ArtDrawer
+	Transformer
+
+	// gs is the graphics state of the path, it is reused by the reader
+	PathRender(op PathOp, gs *GraphicsState)
+
+	// guide, a non-printing path
+	Guide(g *Guide)
+
+	// placed art
+	PlacedArt(obj *PlacedArt)
+
+	// gradient mesh
+	Mesh(m *Mesh)
+
+	// live effects, applied to the next group or path
+	SetEffects(effects []Effect)
+
+	// set color
+	SetColor(op PathOp, c Color)
+	SetOpacity(opacity float64)
+
+	// path attributes
+	SetDash(dash []float64, phase float64)
+	SetFlat(flatness float64)
+	SetLineCap(v LineCap)
+	SetLineJoin(v LineJoin)
+	SetLineWidth(v float64)
+	SetMiterLimit(v float64)
+}
+
+// NewDrawerV2 adapts a Drawer, typed values are formatted back to
+// operands. The methods of DrawerV2 missing from Drawer are forwarded when
+// the Drawer implements them
+func NewDrawerV2(d Drawer) DrawerV2 {
+	return drawerAdapter{d}
+}
+
+type drawerAdapter struct {
+	Drawer
+}
+
+// PushTransform is forwarded when the Drawer implements Transformer
+func (a drawerAdapter) PushTransform(m Matrix) {
+	if t, ok := a.Drawer.(Transformer); ok {
+		t.PushTransform(m)
+	}
+}
+
+func (a drawerAdapter) PopTransform() {
+	if t, ok := a.Drawer.(Transformer); ok {
+		t.PopTransform()
+	}
+}
+
+func (a drawerAdapter) PathRender(op PathOp, gs *GraphicsState) {
+	a.Drawer.PathRender(op)
+}
+
+func (a drawerAdapter) Guide(g *Guide) {
+	if d, ok := a.Drawer.(interface{ Guide(*Guide) }); ok {
+		d.Guide(g)
+	}
+}
+
+func (a drawerAdapter) PlacedArt(obj *PlacedArt) {
+	if d, ok := a.Drawer.(interface{ PlacedArt(*PlacedArt) }); ok {
+		d.PlacedArt(obj)
+	}
+}
+
+func (a drawerAdapter) Mesh(m *Mesh) {
+	if d, ok := a.Drawer.(interface{ Mesh(*Mesh) }); ok {
+		d.Mesh(m)
+	}
+}
+
+func (a drawerAdapter) SetEffects(effects []Effect) {
+	if d, ok := a.Drawer.(interface{ SetEffects([]Effect) }); ok {
+		d.SetEffects(effects)
+	}
+}
+
+func (a drawerAdapter) SetColor(op PathOp, c Color) {
+	if c.IsRGB() {
+		a.Drawer.SetRGB(op, c.ToRGB())
+	} else {
+		a.Drawer.SetCMYK(op, c.ToCMYK())
+	}
+}
+
+func (a drawerAdapter) SetOpacity(opacity float64) {
+	a.Drawer.SetOpacity(formatFloat(opacity))
+}
+
+func (a drawerAdapter) SetDash(dash []float64, phase float64) {
+	a.Drawer.SetDash()
+}
+
+func (a drawerAdapter) SetFlat(flatness float64) {
+	a.Drawer.SetFlat()
+}
+
+func (a drawerAdapter) SetLineCap(v LineCap) {
+	a.Drawer.SetLineCap(strconv.Itoa(int(v)))
+}
+
+func (a drawerAdapter) SetLineJoin(v LineJoin) {
+	a.Drawer.SetLineJoin(strconv.Itoa(int(v)))
+}
+
+func (a drawerAdapter) SetLineWidth(v float64) {
+	a.Drawer.SetLineWidth(formatFloat(v))
+}
+
+func (a drawerAdapter) SetMiterLimit(v float64) {
+	a.Drawer.SetMiterLimit(formatFloat(v))
 }
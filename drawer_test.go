@@ -0,0 +1,86 @@
+package illustrator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// legacyDrawer has the method set of Drawer before DrawerV2, it records
+// the style operands
+type legacyDrawer struct {
+	calls []string
+}
+
+func (d *legacyDrawer) add(call string)    { d.calls = append(d.calls, call) }
+func (d *legacyDrawer) recorded() []string { return d.calls }
+
+func (d *legacyDrawer) SetHeader(*AIHeader)                    {}
+func (d *legacyDrawer) BeginLayer(*AILayer)                    {}
+func (d *legacyDrawer) SetLayerName(name string)               {}
+func (d *legacyDrawer) EndLayer()                              {}
+func (d *legacyDrawer) Group()                                 {}
+func (d *legacyDrawer) EndGroup()                              {}
+func (d *legacyDrawer) SetGroupAttr()                          {}
+func (d *legacyDrawer) ClosePath()                             {}
+func (d *legacyDrawer) PathRender(op PathOp)                   { d.add("PathRender") }
+func (d *legacyDrawer) Moveto(x, y float64)                    {}
+func (d *legacyDrawer) Lineto(x, y float64)                    {}
+func (d *legacyDrawer) Curveto1(x0, y0, x1, y1 float64)        {}
+func (d *legacyDrawer) Curveto2(x1, y1, x2, y2 float64)        {}
+func (d *legacyDrawer) Curveto(x0, y0, x1, y1, x2, y2 float64) {}
+func (d *legacyDrawer) ClipPath()                              {}
+func (d *legacyDrawer) ApplyClip()                             {}
+func (d *legacyDrawer) CompoundPath()                          {}
+func (d *legacyDrawer) EndCompoundPath()                       {}
+func (d *legacyDrawer) SetRGB(PathOp, [3]uint8)                {}
+func (d *legacyDrawer) SetCMYK(PathOp, [4]float64)             {}
+func (d *legacyDrawer) SetOpacity(opacity string)              {}
+func (d *legacyDrawer) SetDash()                               {}
+func (d *legacyDrawer) SetFlat()                               {}
+func (d *legacyDrawer) SetLineCap(v string)                    { d.add("SetLineCap " + v) }
+func (d *legacyDrawer) SetLineJoin(v string)                   {}
+func (d *legacyDrawer) SetLineWidth(v string)                  { d.add("SetLineWidth " + v) }
+func (d *legacyDrawer) SetMiterLimit(v string)                 {}
+func (d *legacyDrawer) DefGradient(g *Gradient)                {}
+func (d *legacyDrawer) SetGradient(g *Gradient)                {}
+func (d *legacyDrawer) SetRaster(obj *Raster)                  {}
+
+// guideDrawer implements the optional Guide
+type guideDrawer struct {
+	legacyDrawer
+}
+
+func (d *guideDrawer) Guide(g *Guide) { d.add("Guide") }
+
+const legacySource = "%%EndComments\n%AI5_BeginLayer\n1 1 1 1 0 0 -1 79 128 255 Lb\n" +
+	"1 J 0.5 w\n0 0 m 10 0 L S\n0 0 m 0 10 L *S\nLB\n"
+
+func TestDrawLegacy(t *testing.T) {
+	tests := []struct {
+		name   string
+		drawer interface {
+			Drawer
+			recorded() []string
+		}
+		want []string
+	}{
+		{"legacy", &legacyDrawer{}, []string{"SetLineCap 1", "SetLineWidth 0.5", "PathRender", "PathRender"}},
+		{"optional guide", &guideDrawer{}, []string{"SetLineCap 1", "SetLineWidth 0.5", "PathRender", "PathRender", "Guide"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewAIReader(strings.NewReader(legacySource))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Draw(tt.drawer); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.drawer.recorded(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
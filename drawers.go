@@ -0,0 +1,674 @@
+package illustrator
+
+// BaseDrawer is a DrawerV2 ignoring every event, embed it to implement
+// only the methods of interest
+type BaseDrawer struct{}
+
+func (BaseDrawer) SetHeader(*AIHeader)                     {}
+func (BaseDrawer) BeginLayer(*AILayer)                     {}
+func (BaseDrawer) SetLayerName(name string)                {}
+func (BaseDrawer) EndLayer()                               {}
+func (BaseDrawer) Group()                                  {}
+func (BaseDrawer) EndGroup()                               {}
+func (BaseDrawer) SetGroupAttr()                           {}
+func (BaseDrawer) ClosePath()                              {}
+func (BaseDrawer) PathRender(op PathOp, gs *GraphicsState) {}
+func (BaseDrawer) Moveto(x, y float64)                     {}
+func (BaseDrawer) Lineto(x, y float64)                     {}
+func (BaseDrawer) Curveto1(x0, y0, x1, y1 float64)         {}
+func (BaseDrawer) Curveto2(x1, y1, x2, y2 float64)         {}
+func (BaseDrawer) Curveto(x0, y0, x1, y1, x2, y2 float64)  {}
+func (BaseDrawer) ClipPath()                               {}
+func (BaseDrawer) ApplyClip()                              {}
+func (BaseDrawer) Guide(g *Guide)                          {}
+func (BaseDrawer) CompoundPath()                           {}
+func (BaseDrawer) EndCompoundPath()                        {}
+func (BaseDrawer) SetColor(op PathOp, c Color)             {}
+func (BaseDrawer) SetOpacity(opacity float64)              {}
+func (BaseDrawer) SetDash(dash []float64, phase float64)   {}
+func (BaseDrawer) SetFlat(flatness float64)                {}
+func (BaseDrawer) SetLineCap(v LineCap)                    {}
+func (BaseDrawer) SetLineJoin(v LineJoin)                  {}
+func (BaseDrawer) SetLineWidth(v float64)                  {}
+func (BaseDrawer) SetMiterLimit(v float64)                 {}
+func (BaseDrawer) DefGradient(g *Gradient)                 {}
+func (BaseDrawer) SetGradient(g *Gradient)                 {}
+func (BaseDrawer) SetRaster(obj *Raster)                   {}
+func (BaseDrawer) PlacedArt(obj *PlacedArt)                {}
+func (BaseDrawer) Mesh(m *Mesh)                            {}
+func (BaseDrawer) SetEffects(effects []Effect)             {}
+func (BaseDrawer) PushTransform(m Matrix)                  {}
+func (BaseDrawer) PopTransform()                           {}
+
+// TeeDrawer forwards every event to all of its drawers in order, the
+// arguments are shared and must not be modified by the drawers
+type TeeDrawer []DrawerV2
+
+func NewTeeDrawer(drawers ...DrawerV2) TeeDrawer {
+	return TeeDrawer(drawers)
+}
+
+func (t TeeDrawer) SetHeader(header *AIHeader) {
+	for _, d := range t {
+		d.SetHeader(header)
+	}
+}
+
+func (t TeeDrawer) BeginLayer(l *AILayer) {
+	for _, d := range t {
+		d.BeginLayer(l)
+	}
+}
+
+func (t TeeDrawer) SetLayerName(name string) {
+	for _, d := range t {
+		d.SetLayerName(name)
+	}
+}
+
+func (t TeeDrawer) EndLayer() {
+	for _, d := range t {
+		d.EndLayer()
+	}
+}
+
+func (t TeeDrawer) Group() {
+	for _, d := range t {
+		d.Group()
+	}
+}
+
+func (t TeeDrawer) EndGroup() {
+	for _, d := range t {
+		d.EndGroup()
+	}
+}
+
+func (t TeeDrawer) SetGroupAttr() {
+	for _, d := range t {
+		d.SetGroupAttr()
+	}
+}
+
+func (t TeeDrawer) ClosePath() {
+	for _, d := range t {
+		d.ClosePath()
+	}
+}
+
+func (t TeeDrawer) PathRender(op PathOp, gs *GraphicsState) {
+	for _, d := range t {
+		d.PathRender(op, gs)
+	}
+}
+
+func (t TeeDrawer) Moveto(x, y float64) {
+	for _, d := range t {
+		d.Moveto(x, y)
+	}
+}
+
+func (t TeeDrawer) Lineto(x, y float64) {
+	for _, d := range t {
+		d.Lineto(x, y)
+	}
+}
+
+func (t TeeDrawer) Curveto1(x0, y0, x1, y1 float64) {
+	for _, d := range t {
+		d.Curveto1(x0, y0, x1, y1)
+	}
+}
+
+func (t TeeDrawer) Curveto2(x1, y1, x2, y2 float64) {
+	for _, d := range t {
+		d.Curveto2(x1, y1, x2, y2)
+	}
+}
+
+func (t TeeDrawer) Curveto(x0, y0, x1, y1, x2, y2 float64) {
+	for _, d := range t {
+		d.Curveto(x0, y0, x1, y1, x2, y2)
+	}
+}
+
+func (t TeeDrawer) ClipPath() {
+	for _, d := range t {
+		d.ClipPath()
+	}
+}
+
+func (t TeeDrawer) ApplyClip() {
+	for _, d := range t {
+		d.ApplyClip()
+	}
+}
+
+func (t TeeDrawer) Guide(g *Guide) {
+	for _, d := range t {
+		d.Guide(g)
+	}
+}
+
+func (t TeeDrawer) CompoundPath() {
+	for _, d := range t {
+		d.CompoundPath()
+	}
+}
+
+func (t TeeDrawer) EndCompoundPath() {
+	for _, d := range t {
+		d.EndCompoundPath()
+	}
+}
+
+func (t TeeDrawer) SetColor(op PathOp, c Color) {
+	for _, d := range t {
+		d.SetColor(op, c)
+	}
+}
+
+func (t TeeDrawer) SetOpacity(opacity float64) {
+	for _, d := range t {
+		d.SetOpacity(opacity)
+	}
+}
+
+func (t TeeDrawer) SetDash(dash []float64, phase float64) {
+	for _, d := range t {
+		d.SetDash(dash, phase)
+	}
+}
+
+func (t TeeDrawer) SetFlat(flatness float64) {
+	for _, d := range t {
+		d.SetFlat(flatness)
+	}
+}
+
+func (t TeeDrawer) SetLineCap(v LineCap) {
+	for _, d := range t {
+		d.SetLineCap(v)
+	}
+}
+
+func (t TeeDrawer) SetLineJoin(v LineJoin) {
+	for _, d := range t {
+		d.SetLineJoin(v)
+	}
+}
+
+func (t TeeDrawer) SetLineWidth(v float64) {
+	for _, d := range t {
+		d.SetLineWidth(v)
+	}
+}
+
+func (t TeeDrawer) SetMiterLimit(v float64) {
+	for _, d := range t {
+		d.SetMiterLimit(v)
+	}
+}
+
+func (t TeeDrawer) DefGradient(g *Gradient) {
+	for _, d := range t {
+		d.DefGradient(g)
+	}
+}
+
+func (t TeeDrawer) SetGradient(g *Gradient) {
+	for _, d := range t {
+		d.SetGradient(g)
+	}
+}
+
+func (t TeeDrawer) SetRaster(obj *Raster) {
+	for _, d := range t {
+		d.SetRaster(obj)
+	}
+}
+
+func (t TeeDrawer) PlacedArt(obj *PlacedArt) {
+	for _, d := range t {
+		d.PlacedArt(obj)
+	}
+}
+
+func (t TeeDrawer) Mesh(m *Mesh) {
+	for _, d := range t {
+		d.Mesh(m)
+	}
+}
+
+func (t TeeDrawer) SetEffects(effects []Effect) {
+	for _, d := range t {
+		d.SetEffects(effects)
+	}
+}
+
+func (t TeeDrawer) PushTransform(m Matrix) {
+	for _, d := range t {
+		d.PushTransform(m)
+	}
+}
+
+func (t TeeDrawer) PopTransform() {
+	for _, d := range t {
+		d.PopTransform()
+	}
+}
+
+type FilterOption struct {
+	Layers     []string   // names of the layers to keep, empty keeps all
+	SkipTypes  []NodeType // object types to drop
+	SkipHidden bool       // drop hidden layers
+}
+
+func SetFilterLayers(names ...string) func(*FilterOption) {
+	return func(fo *FilterOption) {
+		fo.Layers = names
+	}
+}
+
+func SetFilterSkipTypes(types ...NodeType) func(*FilterOption) {
+	return func(fo *FilterOption) {
+		fo.SkipTypes = types
+	}
+}
+
+func SetFilterSkipHidden(v bool) func(*FilterOption) {
+	return func(fo *FilterOption) {
+		fo.SkipHidden = v
+	}
+}
+
+type filterLayer struct {
+	layer   AILayer
+	name    string // raw name of Ln
+	decided bool
+	match   bool // the layer or its parent is in FilterOption.Layers
+	drop    bool
+}
+
+// FilterDrawer drops the events of filtered layers and objects before
+// forwarding them. Style events are always forwarded, so that the graphics
+// state of the next drawer stays in sync.
+type FilterDrawer struct {
+	d   DrawerV2
+	opt FilterOption
+
+	layers   []*filterLayer // open layers, a layer is decided once its name is known
+	skip     int            // nesting depth inside a dropped group or compound path
+	compound bool           // inside a forwarded compound path
+	path     []func(DrawerV2)
+	clip     bool // the buffered path is a clipping path
+	effects  []Effect
+	gradient *Gradient // held like effects until the next painted path is kept
+	pushed   []bool    // whether each open transform was forwarded
+}
+
+func NewFilterDrawer(d DrawerV2, opts ...func(*FilterOption)) *FilterDrawer {
+	f := &FilterDrawer{d: d}
+	for _, opt := range opts {
+		opt(&f.opt)
+	}
+	return f
+}
+
+func (f *FilterDrawer) skipType(t NodeType) bool {
+	for _, v := range f.opt.SkipTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FilterDrawer) matchLayer(name string) bool {
+	if len(f.opt.Layers) == 0 {
+		return true
+	}
+
+	for _, v := range f.opt.Layers {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// decide forwards the open layers that are kept
+func (f *FilterDrawer) decide() {
+	for i, l := range f.layers {
+		if l.decided {
+			continue
+		}
+
+		l.decided = true
+		parentDrop, parentMatch := false, false
+		if i > 0 {
+			parentDrop, parentMatch = f.layers[i-1].drop, f.layers[i-1].match
+		}
+
+		l.match = parentMatch || f.matchLayer(l.layer.Name)
+		l.drop = parentDrop || !l.match || (f.opt.SkipHidden && !l.layer.Visible)
+		if !l.drop {
+			f.d.BeginLayer(&l.layer)
+			if len(l.name) > 0 {
+				f.d.SetLayerName(l.name)
+			}
+		}
+	}
+}
+
+func (f *FilterDrawer) visible() bool {
+	f.decide()
+	if l := len(f.layers); l > 0 && f.layers[l-1].drop {
+		return false
+	}
+	return f.skip == 0
+}
+
+// bufferPath reports whether the path construction is held back until
+// the path is known to be a clipping path
+func (f *FilterDrawer) bufferPath() bool {
+	return !f.compound && f.skipType(NodePath)
+}
+
+func (f *FilterDrawer) segment(fn func(DrawerV2)) {
+	if !f.visible() {
+		return
+	}
+
+	if f.bufferPath() {
+		f.path = append(f.path, fn)
+	} else {
+		fn(f.d)
+	}
+}
+
+func (f *FilterDrawer) flushPath() {
+	for _, fn := range f.path {
+		fn(f.d)
+	}
+	f.path = f.path[:0]
+}
+
+func (f *FilterDrawer) flushGradient() {
+	if f.gradient != nil {
+		f.d.SetGradient(f.gradient)
+		f.gradient = nil
+	}
+}
+
+func (f *FilterDrawer) flushEffects() {
+	if f.effects != nil {
+		f.d.SetEffects(f.effects)
+		f.effects = nil
+	}
+}
+
+func (f *FilterDrawer) SetHeader(header *AIHeader) {
+	f.d.SetHeader(header)
+}
+
+func (f *FilterDrawer) BeginLayer(l *AILayer) {
+	f.layers = append(f.layers, &filterLayer{layer: *l})
+}
+
+func (f *FilterDrawer) SetLayerName(name string) {
+	if l := len(f.layers); l > 0 && !f.layers[l-1].decided {
+		f.layers[l-1].name = name
+		f.layers[l-1].layer.Name = toString(name)
+		return
+	}
+
+	if f.visible() {
+		f.d.SetLayerName(name)
+	}
+}
+
+func (f *FilterDrawer) EndLayer() {
+	f.decide()
+	l := len(f.layers)
+	if l == 0 {
+		return
+	}
+
+	layer := f.layers[l-1]
+	f.layers = f.layers[:l-1]
+	if !layer.drop {
+		f.d.EndLayer()
+	}
+}
+
+func (f *FilterDrawer) Group() {
+	if !f.visible() {
+		if f.skip > 0 {
+			f.skip++
+		}
+		return
+	}
+
+	if f.skipType(NodeGroup) {
+		f.skip++
+		f.effects = nil
+		return
+	}
+
+	f.flushEffects()
+	f.d.Group()
+}
+
+func (f *FilterDrawer) EndGroup() {
+	if f.skip > 0 {
+		f.skip--
+		return
+	}
+
+	if f.visible() {
+		f.d.EndGroup()
+	}
+}
+
+func (f *FilterDrawer) SetGroupAttr() {
+	if f.visible() {
+		f.d.SetGroupAttr()
+	}
+}
+
+func (f *FilterDrawer) ClosePath() {
+	f.segment(func(d DrawerV2) { d.ClosePath() })
+}
+
+func (f *FilterDrawer) PathRender(op PathOp, gs *GraphicsState) {
+	if !f.visible() {
+		return
+	}
+
+	if f.bufferPath() && !f.clip {
+		// a dropped path
+		f.path = f.path[:0]
+		if op != 0 && !f.compound {
+			f.effects = nil
+			f.gradient = nil
+		}
+		return
+	}
+
+	f.clip = false
+	f.flushPath()
+	if op != 0 {
+		f.flushGradient()
+		if !f.compound {
+			f.flushEffects()
+		}
+	}
+	f.d.PathRender(op, gs)
+}
+
+func (f *FilterDrawer) Moveto(x, y float64) {
+	f.segment(func(d DrawerV2) { d.Moveto(x, y) })
+}
+
+func (f *FilterDrawer) Lineto(x, y float64) {
+	f.segment(func(d DrawerV2) { d.Lineto(x, y) })
+}
+
+func (f *FilterDrawer) Curveto1(x0, y0, x1, y1 float64) {
+	f.segment(func(d DrawerV2) { d.Curveto1(x0, y0, x1, y1) })
+}
+
+func (f *FilterDrawer) Curveto2(x1, y1, x2, y2 float64) {
+	f.segment(func(d DrawerV2) { d.Curveto2(x1, y1, x2, y2) })
+}
+
+func (f *FilterDrawer) Curveto(x0, y0, x1, y1, x2, y2 float64) {
+	f.segment(func(d DrawerV2) { d.Curveto(x0, y0, x1, y1, x2, y2) })
+}
+
+func (f *FilterDrawer) ClipPath() {
+	f.segment(func(d DrawerV2) { d.ClipPath() })
+}
+
+func (f *FilterDrawer) ApplyClip() {
+	if !f.visible() {
+		return
+	}
+
+	// clipping paths are kept with their group
+	f.clip = f.bufferPath()
+	f.flushPath()
+	f.d.ApplyClip()
+}
+
+func (f *FilterDrawer) Guide(g *Guide) {
+	if f.visible() {
+		f.d.Guide(g)
+	}
+}
+
+func (f *FilterDrawer) CompoundPath() {
+	if !f.visible() {
+		if f.skip > 0 {
+			f.skip++
+		}
+		return
+	}
+
+	if f.skipType(NodeCompoundPath) {
+		f.skip++
+		f.effects = nil
+		f.gradient = nil
+		return
+	}
+
+	f.compound = true
+	f.d.CompoundPath()
+}
+
+func (f *FilterDrawer) EndCompoundPath() {
+	if f.skip > 0 {
+		f.skip--
+		return
+	}
+
+	if f.visible() {
+		f.compound = false
+		f.flushGradient()
+		f.flushEffects()
+		f.d.EndCompoundPath()
+	}
+}
+
+func (f *FilterDrawer) SetColor(op PathOp, c Color) {
+	f.d.SetColor(op, c)
+}
+
+func (f *FilterDrawer) SetOpacity(opacity float64) {
+	f.d.SetOpacity(opacity)
+}
+
+func (f *FilterDrawer) SetDash(dash []float64, phase float64) {
+	f.d.SetDash(dash, phase)
+}
+
+func (f *FilterDrawer) SetFlat(flatness float64) {
+	f.d.SetFlat(flatness)
+}
+
+func (f *FilterDrawer) SetLineCap(v LineCap) {
+	f.d.SetLineCap(v)
+}
+
+func (f *FilterDrawer) SetLineJoin(v LineJoin) {
+	f.d.SetLineJoin(v)
+}
+
+func (f *FilterDrawer) SetLineWidth(v float64) {
+	f.d.SetLineWidth(v)
+}
+
+func (f *FilterDrawer) SetMiterLimit(v float64) {
+	f.d.SetMiterLimit(v)
+}
+
+func (f *FilterDrawer) DefGradient(g *Gradient) {
+	f.d.DefGradient(g)
+}
+
+// SetGradient is held back until the next painted path is known to be kept
+func (f *FilterDrawer) SetGradient(g *Gradient) {
+	if f.visible() {
+		gradient := *g
+		f.gradient = &gradient
+	}
+}
+
+func (f *FilterDrawer) SetRaster(obj *Raster) {
+	if f.visible() && !f.skipType(NodeRaster) {
+		f.d.SetRaster(obj)
+	}
+}
+
+func (f *FilterDrawer) PlacedArt(obj *PlacedArt) {
+	if f.visible() && !f.skipType(NodePlacedArt) {
+		f.d.PlacedArt(obj)
+	}
+}
+
+func (f *FilterDrawer) Mesh(m *Mesh) {
+	if f.visible() && !f.skipType(NodeMesh) {
+		f.d.Mesh(m)
+	}
+}
+
+// SetEffects is held back until the next group or path is known to be kept
+func (f *FilterDrawer) SetEffects(effects []Effect) {
+	if f.visible() {
+		f.effects = effects
+	}
+}
+
+// PushTransform is forwarded only in kept art, PopTransform forwards the
+// pops of the forwarded pushes
+func (f *FilterDrawer) PushTransform(m Matrix) {
+	forward := f.visible()
+	f.pushed = append(f.pushed, forward)
+	if forward {
+		f.d.PushTransform(m)
+	}
+}
+
+func (f *FilterDrawer) PopTransform() {
+	l := len(f.pushed)
+	if l == 0 {
+		return
+	}
+
+	forward := f.pushed[l-1]
+	f.pushed = f.pushed[:l-1]
+	if forward {
+		f.d.PopTransform()
+	}
+}
@@ -0,0 +1,139 @@
+package illustrator
+
+import (
+	"math"
+	"strings"
+)
+
+var (
+	AI9_BeginPluginObject = []byte("%AI9_BeginPluginObject")
+	AI9_EndPluginObject   = []byte("%AI9_EndPluginObject")
+)
+
+type EffectType int8
+
+const (
+	EffectUnknown EffectType = iota
+	EffectDropShadow
+	EffectGaussianBlur
+	EffectFeather
+	EffectOuterGlow
+	EffectInnerGlow
+)
+
+// Effect is a live effect applied to the following art
+type Effect struct {
+	Type EffectType
+	Name string
+
+	Dx      float64 // horizontal offset
+	Dy      float64 // vertical offset, positive is down
+	Blur    float64 // blur radius
+	Opacity float64 // between 0-1
+	Color   [3]uint8
+
+	Params Dict // raw effect parameters
+}
+
+func effectType(name string) EffectType {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "drop shadow"):
+		return EffectDropShadow
+	case strings.Contains(name, "gaussian blur"):
+		return EffectGaussianBlur
+	case strings.Contains(name, "feather"), strings.Contains(name, "fuzzy mask"):
+		return EffectFeather
+	case strings.Contains(name, "outer glow"):
+		return EffectOuterGlow
+	case strings.Contains(name, "inner glow"):
+		return EffectInnerGlow
+	}
+	return EffectUnknown
+}
+
+func effectColor(v interface{}) [3]uint8 {
+	var rgb [3]uint8
+	vals, _ := v.([]interface{})
+	if len(vals) < 3 {
+		return rgb
+	}
+
+	for i := range rgb {
+		switch c := vals[i].(type) {
+		case float64:
+			rgb[i] = uint8(math.Round(c * 255))
+		case int:
+			rgb[i] = uint8(c * 255)
+		}
+	}
+	return rgb
+}
+
+// newEffect builds an effect from a dictionary such as
+//
+//	(Adobe Drop Shadow) /String (Name) ,
+//	/Dictionary : 7 /Real (horz) , 7 /Real (vert) , ... ; (Params) ,
+func newEffect(d Dict) *Effect {
+	name := d.String("Name")
+	params := d.Dict("Params")
+	if params == nil {
+		params = d
+	}
+
+	effect := &Effect{
+		Type:    effectType(name),
+		Name:    name,
+		Opacity: 1,
+		Params:  params,
+	}
+	if effect.Type == EffectUnknown {
+		return nil
+	}
+
+	switch effect.Type {
+	case EffectDropShadow:
+		effect.Dx = params.Float("horz")
+		effect.Dy = params.Float("vert")
+		effect.Blur = params.Float("blur")
+		effect.Color = effectColor(params["sdwColor"])
+	case EffectGaussianBlur:
+		effect.Blur = params.Float("blur")
+	case EffectFeather:
+		effect.Blur = params.Float("radi")
+	case EffectOuterGlow, EffectInnerGlow:
+		effect.Blur = params.Float("blur")
+		effect.Color = effectColor(params["color"])
+	}
+
+	if _, ok := params["opac"]; ok {
+		effect.Opacity = params.Float("opac")
+	}
+
+	return effect
+}
+
+// EffectsArgs collects the effects of a plugin object, either listed
+// in an /Effects array or given as top level dictionaries
+func EffectsArgs(dicts []Dict) []Effect {
+	var effects []Effect
+	for _, d := range dicts {
+		list := d.Array("Effects")
+		if list == nil {
+			list = []interface{}{d}
+		}
+
+		for _, v := range list {
+			item, _ := v.(Dict)
+			if item == nil {
+				continue
+			}
+
+			if effect := newEffect(item); effect != nil {
+				effects = append(effects, *effect)
+			}
+		}
+	}
+
+	return effects
+}
@@ -8,6 +8,8 @@ type Gradient struct {
 	nColors int // number of colors in gradient
 
 	Colors []OffColor
+
+	Matrix Matrix // gradient instance matrix, Bm
 }
 
 func (g *Gradient) AddColor(color *OffColor) {
@@ -37,39 +39,52 @@ func (sc *OffColor) Opacity() float64 {
 func BSArgs(vals []string) *OffColor {
 	var stop OffColor
 	var l = len(vals)
+	if l < 3 {
+		return nil
+	}
 
 	stop.offset = toFloat(vals[l-1]) / 100
 	stop.midPoint = toFloat(vals[l-2])
 
 	colorSpace := vals[l-3]
+	alt := "" // color space of custom colors, after name and tint
+	if l >= 5 {
+		alt = vals[l-5]
+	}
 	switch colorSpace {
 	case "0":
 		if l-3 == 1 {
 		} else {
-			colorSpace = vals[l-5]
+			colorSpace = alt
 		}
 	case "1":
 		if l-3 == 4 {
 		} else {
-			colorSpace = vals[l-5]
+			colorSpace = alt
 		}
 	case "2":
 		if l-3 == 7 {
 		} else {
-			colorSpace = vals[l-5]
+			colorSpace = alt
 		}
 	case "3":
 		if l-3 == 6 {
 		} else {
-			colorSpace = vals[l-5]
+			colorSpace = alt
 		}
 	case "4":
 		if l-3 == 10 {
 		} else {
-			colorSpace = vals[l-5]
+			colorSpace = alt
 		}
 	default:
-		colorSpace = vals[l-5]
+		colorSpace = alt
+	}
+
+	// operands of the color, before color space, midpoint and offset
+	need := map[string]int{"0": 1, "1": 4, "2": 7, "3": 6, "4": 9}[colorSpace]
+	if need == 0 || l < need {
+		return nil
 	}
 
 	switch colorSpace {
@@ -0,0 +1,128 @@
+package illustrator
+
+type LineCap int8
+
+const (
+	ButtCap LineCap = iota
+	RoundCap
+	SquareCap
+)
+
+type LineJoin int8
+
+const (
+	MiterJoin LineJoin = iota
+	RoundJoin
+	BevelJoin
+)
+
+// GraphicsState is the paint state in effect for a path
+type GraphicsState struct {
+	Fill   Color
+	Stroke Color
+
+	LineWidth  float64
+	LineCap    LineCap
+	LineJoin   LineJoin
+	MiterLimit float64
+	Dash       []float64
+	DashPhase  float64
+
+	CTM        Matrix // current transformation matrix
+	transforms int    // transforms pushed to the drawer
+
+	Opacity         float64
+	FillOverprint   bool
+	StrokeOverprint bool
+	EvenOdd         bool // fill rule, false is non-zero winding
+}
+
+func NewGraphicsState() GraphicsState {
+	black := CMYKColor(0, 0, 0, 1)
+	return GraphicsState{
+		Fill:       black,
+		Stroke:     black,
+		LineWidth:  1,
+		MiterLimit: 4,
+		CTM:        Identity(),
+		Opacity:    1,
+	}
+}
+
+// Clone returns a copy not sharing the dash array
+func (gs *GraphicsState) Clone() GraphicsState {
+	c := *gs
+	if gs.Dash != nil {
+		c.Dash = make([]float64, len(gs.Dash))
+		copy(c.Dash, gs.Dash)
+	}
+	return c
+}
+
+func (gs *GraphicsState) setColor(op PathOp, c Color) {
+	if op&AI_Fill == AI_Fill {
+		gs.Fill = c
+	} else if op&AI_Stroke == AI_Stroke {
+		gs.Stroke = c
+	}
+}
+
+// gsave pushes a copy of the graphics state, on u, q and *u
+func (r *AIReader) gsave() {
+	r.gstack = append(r.gstack, r.gs.Clone())
+}
+
+// grestore pops the graphics state, on U, Q and *U, the transforms
+// concatenated since gsave are popped from the drawer
+func (r *AIReader) grestore(d DrawerV2) {
+	if l := len(r.gstack); l > 0 {
+		r.popTransforms(d, r.gstack[l-1].transforms)
+		r.gs = r.gstack[l-1]
+		r.gstack = r.gstack[:l-1]
+	}
+}
+
+// popTransforms pops the transforms pushed after the first n
+func (r *AIReader) popTransforms(d DrawerV2, n int) {
+	for ; r.gs.transforms > n; r.gs.transforms-- {
+		d.PopTransform()
+	}
+}
+
+// layerMark is the transformation in effect at the start of a layer
+type layerMark struct {
+	ctm        Matrix
+	transforms int
+}
+
+// endLayer pops the transforms concatenated in the layer outside of any
+// group, on LB
+func (r *AIReader) endLayer(d DrawerV2) {
+	if l := len(r.layers); l > 0 {
+		mark := r.layers[l-1]
+		r.layers = r.layers[:l-1]
+		r.popTransforms(d, mark.transforms)
+		r.gs.CTM = mark.ctm
+	}
+	d.EndLayer()
+}
+
+// concat multiplies the current transformation matrix by m
+func (r *AIReader) concat(d DrawerV2, m Matrix) {
+	r.gs.CTM = m.Multiply(r.gs.CTM)
+	r.gs.transforms++
+	d.PushTransform(m)
+}
+
+// DArgs parses the dash pattern: [ array ] phase d
+func DArgs(vals []string) (dash []float64, phase float64) {
+	l := len(vals)
+	if l < 3 || vals[0] != "[" || vals[l-2] != "]" {
+		return nil, 0
+	}
+
+	if l > 3 {
+		dash = toFloatSlice(vals[1 : l-2])
+	}
+	return dash, toFloat(vals[l-1])
+}
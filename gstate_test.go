@@ -0,0 +1,88 @@
+package illustrator
+
+import (
+	"strings"
+	"testing"
+)
+
+const layerConcat = `%%EndComments
+%AI5_BeginLayer
+1 1 1 1 0 0 -1 79 128 255 Lb
+(Layer 1) Ln
+[ 1 0 0 1 10 20 ] concat
+u
+[ 2 0 0 2 0 0 ] concat
+0 0 m 10 0 L f
+U
+0 0 m 1 1 L S
+LB
+%AI5_EndLayer--
+%AI5_BeginLayer
+1 1 1 1 0 0 -1 79 128 255 Lb
+(Layer 2) Ln
+0 0 m 1 1 L S
+LB
+%AI5_EndLayer--
+`
+
+func methods(calls []Call) string {
+	names := make([]string, len(calls))
+	for i, c := range calls {
+		names[i] = c.Method
+	}
+	return strings.Join(names, " ")
+}
+
+func TestLayerTransforms(t *testing.T) {
+	r, _ := NewAIReader(strings.NewReader(layerConcat))
+	rec := NewRecordingDrawer(nil)
+	if err := r.DrawV2(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	got := methods(rec.Calls)
+	want := "SetHeader BeginLayer SetLayerName PushTransform " +
+		"Group PushTransform Moveto Lineto ClosePath PathRender PopTransform EndGroup " +
+		"Moveto Lineto PathRender PopTransform EndLayer " +
+		"BeginLayer SetLayerName Moveto Lineto PathRender EndLayer"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	r, _ = NewAIReader(strings.NewReader(layerConcat))
+	doc, err := r.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Layers) != 2 {
+		t.Fatalf("got %d layers, want 2", len(doc.Layers))
+	}
+	if n := len(doc.Layers[1].Children); n != 1 {
+		t.Errorf("got %d nodes in the second layer, want 1", n)
+	}
+}
+
+func TestFilterTransforms(t *testing.T) {
+	r, _ := NewAIReader(strings.NewReader(layerConcat))
+	rec := NewRecordingDrawer(nil)
+	if err := r.DrawV2(NewFilterDrawer(rec, SetFilterLayers("Layer 2"))); err != nil {
+		t.Fatal(err)
+	}
+
+	var depth int
+	for _, c := range rec.Calls {
+		switch c.Method {
+		case "PushTransform":
+			depth++
+		case "PopTransform":
+			depth--
+		case "BeginLayer":
+			if depth != 0 {
+				t.Errorf("layer begins with %d transforms open", depth)
+			}
+		}
+	}
+	if depth != 0 {
+		t.Errorf("%d transforms left open", depth)
+	}
+}
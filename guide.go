@@ -0,0 +1,41 @@
+package illustrator
+
+import "math"
+
+// Guide is a non-printing guide path
+type Guide struct {
+	Points      [][2]float64 `json:"points"`
+	Orientation string       `json:"orientation,omitempty"` // horizontal or vertical for guide lines
+}
+
+func NewGuide(points [][2]float64) *Guide {
+	g := &Guide{
+		Points: make([][2]float64, len(points)),
+	}
+	copy(g.Points, points)
+
+	if len(points) == 2 {
+		dx := math.Abs(points[1][0] - points[0][0])
+		dy := math.Abs(points[1][1] - points[0][1])
+		if dy < 1e-6 {
+			g.Orientation = "horizontal"
+		} else if dx < 1e-6 {
+			g.Orientation = "vertical"
+		}
+	}
+
+	return g
+}
+
+// isPaintOp reports whether op ends the current path
+func isPaintOp(op string) bool {
+	if len(op) == 2 && op[0] == '*' {
+		op = op[1:]
+	}
+
+	switch op {
+	case "f", "F", "s", "S", "b", "B", "n", "N":
+		return true
+	}
+	return false
+}
@@ -3,10 +3,16 @@ package illustrator
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"strings"
 )
 
+var ErrSyntax = errors.New("illustrator: syntax error")
+
 var (
 	AI5_EndRaster   = []byte("%AI5_EndRaster")
 	AI5_BeginRaster = []byte("%AI5_BeginRaster")
@@ -15,65 +21,198 @@ var (
 type AIReader struct {
 	*bufio.Reader
 
-	lineBuf Buffer
+	lexer    *Lexer
+	line     []byte       // current line, see readLine
+	token    Token        // current token, see next
+	operands operandStack // operands of the next operator
+	err      error        // first read or syntax error, stops the reader
+
+	gs     GraphicsState   // current graphics state
+	gstack []GraphicsState // saved graphics states
+	layers []layerMark     // open layers, see endLayer
+
+	resources   Resources
+	resolveLink LinkResolver
+
+	ctx         context.Context // see DrawContext
+	limits      Limits
+	tokens      int
+	rasterBytes int64
+
+	counter   *countingReader
+	source    positionReader // counts the compressed private data
+	length    int64          // length of private data, see SetLength
+	progress  ProgressFunc
+	reported  int64  // bytes consumed at the last progress report
+	layerName string // current layer
 }
 
 func NewAIReader(r io.Reader) (*AIReader, error) {
+	counter := &countingReader{r: r}
 	reader := &AIReader{
-		Reader: bufio.NewReader(r),
+		Reader:  bufio.NewReader(counter),
+		counter: counter,
+		length:  readerLength(r),
+	}
+	if p, ok := r.(positionReader); ok {
+		reader.source = p
+		_, reader.length = p.position()
 	}
+	reader.lexer = NewLexer(reader.Reader)
+	reader.gs = NewGraphicsState()
 
 	return reader, nil
 }
 
 func (r *AIReader) Bytes() []byte {
-	return r.lineBuf.Bytes()
+	return r.line
+}
+
+// Line returns the current line number of the private data
+func (r *AIReader) Line() int {
+	return r.lexer.Line()
+}
+
+// Resources returns the brushes and graphic styles read from the setup section
+func (r *AIReader) Resources() *Resources {
+	return &r.resources
+}
+
+// SetLinkResolver sets the resolver used for the links of placed art
+func (r *AIReader) SetLinkResolver(fn LinkResolver) {
+	r.resolveLink = fn
 }
 
+// fail records the first error, the reader stops at the next token
+func (r *AIReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// popN pops the n operands of the current operator, nil when the stack
+// holds fewer
+func (r *AIReader) popN(n int) []string {
+	if r.operands.Len() < n {
+		r.fail(fmt.Errorf("%w: line %d: %s needs %d operands, got %d",
+			ErrSyntax, r.token.Line, r.token.Text, n, r.operands.Len()))
+		return nil
+	}
+	return r.operands.PopN(n)
+}
+
+// popAll pops all operands of the current operator, nil when there are
+// fewer than n
+func (r *AIReader) popAll(n int) []string {
+	if r.operands.Len() < n {
+		r.popN(n)
+		return nil
+	}
+	return r.operands.PopAll()
+}
+
+// readLine reads the next non empty line, used by the header comments
 func (r *AIReader) readLine() bool {
-	var n int
-	r.lineBuf.Reset()
-	for {
-		ch, err := r.ReadByte()
+	for r.err == nil {
+		line, err := r.lexer.ReadLine()
 		if err == io.EOF {
-			break
+			return false
 		}
 
 		if err != nil {
-			panic(err)
+			r.fail(err)
+			return false
 		}
 
-		if ch == '\r' || ch == '\n' {
-			if n > 0 {
-				break
-			}
-		} else {
-			r.lineBuf.WriteByte(ch)
-			n++
+		if len(line) > 0 {
+			r.line = line
+			return true
 		}
 	}
+	return false
+}
+
+// next reads the next token
+func (r *AIReader) next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	token, err := r.lexer.Next()
+	if err == io.EOF {
+		return false
+	}
+
+	if err != nil {
+		r.fail(err)
+		return false
+	}
 
-	return r.lineBuf.len > 0
+	r.token = token
+	r.checkToken()
+	r.reportProgress(false)
+	return r.err == nil
 }
 
+// nextOp reads up to the next operator or comment, pushing the operands
+// onto the operand stack
+func (r *AIReader) nextOp() (op string, ok bool) {
+	for r.next() {
+		switch r.token.Kind {
+		case TokenOperator, TokenComment:
+			return r.token.Text, true
+		default:
+			r.operands.Push(r.token.Text)
+		}
+	}
+
+	return "", false
+}
+
+// isComment reports whether the current token is the comment c
+func (r *AIReader) isComment(c []byte) bool {
+	return r.token.Kind == TokenComment && r.token.Text == string(c)
+}
+
+// hasComment reports whether the current token is a comment starting with c
+func (r *AIReader) hasComment(c []byte) bool {
+	return r.token.Kind == TokenComment && strings.HasPrefix(r.token.Text, string(c))
+}
+
+// Draw reads the artwork into a Drawer
 func (r *AIReader) Draw(drawer Drawer) error {
+	return r.DrawV2(NewDrawerV2(drawer))
+}
+
+// DrawV2 reads the artwork into a DrawerV2
+func (r *AIReader) DrawV2(drawer DrawerV2) error {
+	if r.limits.MaxNodes > 0 {
+		drawer = &nodeCounter{DrawerV2: drawer, r: r, max: r.limits.MaxNodes}
+	}
+
 	header := r.readHeader()
 	drawer.SetHeader(header)
 
 	BeginSetup := []byte("%%BeginSetup")
 	BeginProlog := []byte("%%BeginProlog")
 	BeginLayer := []byte("%AI5_BeginLayer")
-	for r.readLine() {
-		line := r.Bytes()
-		if bytes.HasPrefix(line, BeginSetup) {
+	for _, ok := r.nextOp(); ok; _, ok = r.nextOp() {
+		r.operands.Reset()
+		if r.hasComment(BeginSetup) {
 			r.readSetup(drawer)
-		} else if bytes.HasPrefix(line, BeginProlog) {
+		} else if r.hasComment(BeginProlog) {
 			r.readProlog()
-		} else if bytes.HasPrefix(line, BeginLayer) {
+		} else if r.hasComment(BeginLayer) {
 			r.drawLayer(drawer)
 		}
 	}
 
+	if r.err != nil {
+		return r.err
+	}
+	// transforms concatenated outside of any layer
+	r.popTransforms(drawer, 0)
+	r.reportProgress(true)
 	return nil
 }
 
@@ -113,10 +252,9 @@ func (r *AIReader) readProlog() *AIProlog {
 	// %%EndProlog
 	var prolog AIProlog
 	EndProlog := []byte("%%EndProlog")
-	for r.readLine() {
-		line := r.Bytes()
-
-		if bytes.HasPrefix(line, EndProlog) {
+	for _, ok := r.nextOp(); ok; _, ok = r.nextOp() {
+		r.operands.Reset()
+		if r.hasComment(EndProlog) {
 			break
 		}
 		// todo
@@ -125,210 +263,299 @@ func (r *AIReader) readProlog() *AIProlog {
 	return &prolog
 }
 
-func (r *AIReader) readSetup(drawer Drawer) {
+func (r *AIReader) readSetup(drawer DrawerV2) {
 	// %%BeginSetup
 	// %%EndSetup
-	XI := []byte("XI")
-	Bd := []byte(" Bd")
 	EndSetup := []byte("%%EndSetup")
-	for r.readLine() {
-		line := r.Bytes()
+	for op, ok := r.nextOp(); ok; op, ok = r.nextOp() {
+		if r.token.Kind == TokenComment {
+			if r.hasComment(EndSetup) {
+				break
+			}
 
-		if bytes.HasPrefix(line, EndSetup) {
-			break
+			if r.isComment(AI8_BeginBrushPattern) {
+				r.resources.addBrushes(r.readDict(AI8_EndBrushPattern))
+			} else if r.isComment(AI9_BeginArtStyles) {
+				r.resources.addArtStyles(r.readDict(AI9_EndArtStyles))
+			}
+			continue
 		}
 
 		// todo
-		if bytes.HasSuffix(line, XI) {
+		switch op {
+		case "XI":
+			r.skipLine()
 			r.readRasterData()
-		}
-
-		if bytes.HasSuffix(line, Bd) {
+		case "Bd":
 			r.defGradient(drawer)
 		}
+		r.operands.Reset()
 	}
 }
 
-func (r *AIReader) drawLayer(d Drawer) {
-	var token lineToken
-	for r.readLine() {
-		line := r.Bytes()
+// readDict reads the serialized dictionaries up to the end comment
+func (r *AIReader) readDict(end []byte) []Dict {
+	var parser dictParser
+	for r.next() {
+		if r.token.Kind == TokenComment {
+			if r.isComment(end) {
+				break
+			}
+			continue // skip comment
+		}
+
+		parser.push(r.token.Text)
+	}
 
-		if line[0] == '%' {
-			if bytes.Equal(AI5_BeginRaster, line) {
+	return parser.roots
+}
+
+func (r *AIReader) drawLayer(d DrawerV2) {
+	var placed *PlacedArt
+	var points [][2]float64 // anchor points of the current path, used by guides
+	token := &r.operands
+	for op, ok := r.nextOp(); ok; op, ok = r.nextOp() {
+		if r.token.Kind == TokenComment {
+			if r.isComment(AI5_BeginRaster) {
 				r.beginRaster(d)
+			} else if r.isComment(AI8_BeginMesh) {
+				r.beginMesh(d)
+			} else if r.isComment(AI9_BeginPluginObject) {
+				effects := EffectsArgs(r.readDict(AI9_EndPluginObject))
+				if len(effects) > 0 {
+					d.SetEffects(effects)
+				}
 			}
 			// skip comments
 			continue
 		}
 
-		token.parse(line)
-		for token.len > 0 {
-			op := token.Pop()
-			switch op {
-			case "A": // locking, 0-unlocking; 1-locking
-				token.Pop()
-			case "Ap": // show center point
-				token.Pop()
-			case "Lb":
-				r.beginLayer(d, token.PopAll())
-			case "LB":
-				d.EndLayer()
-			case "Ln":
-				d.SetLayerName(token.Pop())
-			case "O", "R": // fill/stroke overprint
-				token.Pop()
-			case "d": // setdash
-				token.Pop()
-			case "D": //
-			case "i": // setflat
-				token.Pop()
-			case "j": // linejoin
-				d.SetLineJoin(token.Pop())
-			case "J": // linecap
-				d.SetLineCap(token.Pop())
-			case "w": // linewidth
-				d.SetLineWidth(token.Pop())
-			case "M": // setmiterlimit
-				d.SetMiterLimit(token.Pop())
-			case "f": // fill
-				d.ClosePath()
-				d.PathRender(AI_Fill)
-			case "F":
-				d.PathRender(AI_Fill)
-			case "s": // stroke
-				d.ClosePath()
-				d.PathRender(AI_Stroke)
-			case "S":
-				d.PathRender(AI_Stroke)
-			case "b": // fill and stroke
-				d.ClosePath()
-				d.PathRender(AI_Fill | AI_Stroke)
-			case "B":
-				d.PathRender(AI_Fill | AI_Stroke)
-			case "h": // close path
-				d.ClosePath()
-				d.ClipPath()
-			case "H": // close path
-				d.ClipPath()
-			case "W": // clip
-				d.ApplyClip()
-			case "n": // no fill no stroke
-				d.PathRender(0)
-			case "N":
-				d.ClosePath()
-				d.PathRender(0)
-			case "u": // begin group
-				d.Group()
-			case "U": // end group
-				d.EndGroup()
-			case "q": // begin clip group
-				d.Group()
-			case "Q": // end  group
-				d.EndGroup()
-			case "*u": // begin compound path
-				d.CompoundPath()
-			case "*U": // end compound path
-				d.EndCompoundPath()
-			case "m":
-				args := token.PopN(2)
+		switch op {
+		case "A": // locking, 0-unlocking; 1-locking
+			token.Pop()
+		case "Ap": // show center point
+			token.Pop()
+		case "Lb":
+			if args := r.popAll(10); args != nil {
+				r.beginLayer(d, args)
+			}
+		case "LB":
+			r.endLayer(d)
+		case "Ln":
+			name := token.Pop()
+			r.setLayerName(toString(name))
+			d.SetLayerName(name)
+		case "O": // fill overprint
+			r.gs.FillOverprint = token.Pop() == "1"
+		case "R": // stroke overprint
+			r.gs.StrokeOverprint = token.Pop() == "1"
+		case "d": // setdash
+			r.gs.Dash, r.gs.DashPhase = DArgs(token.PopAll())
+			d.SetDash(r.gs.Dash, r.gs.DashPhase)
+		case "D": // reverse winding order
+			token.Pop()
+		case "i": // setflat
+			d.SetFlat(toFloat(token.Pop()))
+		case "j": // linejoin
+			r.gs.LineJoin = LineJoin(toInt8(token.Pop()))
+			d.SetLineJoin(r.gs.LineJoin)
+		case "J": // linecap
+			r.gs.LineCap = LineCap(toInt8(token.Pop()))
+			d.SetLineCap(r.gs.LineCap)
+		case "w": // linewidth
+			r.gs.LineWidth = toFloat(token.Pop())
+			d.SetLineWidth(r.gs.LineWidth)
+		case "M": // setmiterlimit
+			r.gs.MiterLimit = toFloat(token.Pop())
+			d.SetMiterLimit(r.gs.MiterLimit)
+		case "f": // fill
+			d.ClosePath()
+			d.PathRender(AI_Fill, &r.gs)
+		case "F":
+			d.PathRender(AI_Fill, &r.gs)
+		case "s": // stroke
+			d.ClosePath()
+			d.PathRender(AI_Stroke, &r.gs)
+		case "S":
+			d.PathRender(AI_Stroke, &r.gs)
+		case "b": // fill and stroke
+			d.ClosePath()
+			d.PathRender(AI_Fill|AI_Stroke, &r.gs)
+		case "B":
+			d.PathRender(AI_Fill|AI_Stroke, &r.gs)
+		case "h": // close path
+			d.ClosePath()
+			d.ClipPath()
+		case "H": // close path
+			d.ClipPath()
+		case "W": // clip
+			d.ApplyClip()
+		case "n": // no fill no stroke
+			d.PathRender(0, &r.gs)
+		case "N":
+			d.ClosePath()
+			d.PathRender(0, &r.gs)
+		case "u": // begin group
+			r.gsave()
+			d.Group()
+		case "U": // end group
+			r.grestore(d)
+			d.EndGroup()
+		case "q": // begin clip group
+			r.gsave()
+			d.Group()
+		case "Q": // end  group
+			r.grestore(d)
+			d.EndGroup()
+		case "*f", "*F", "*s", "*S", "*b", "*B", "*n", "*N": // guide
+			d.PathRender(0, &r.gs)
+			if len(points) > 0 {
+				d.Guide(NewGuide(r.transformPoints(points)))
+			}
+		case "concat": // [ a b c d tx ty ] concat
+			r.concat(d, MatrixArgs(token.PopAll()))
+		case "*u": // begin compound path
+			r.gsave()
+			d.CompoundPath()
+		case "*U": // end compound path
+			r.grestore(d)
+			d.EndCompoundPath()
+		case "m":
+			if args := r.popN(2); args != nil {
 				x := toFloat(args[0])
 				y := toFloat(args[1])
 				d.Moveto(x, y)
-			case "l", "L":
-				args := token.PopN(2)
+				points = append(points, [2]float64{x, y})
+			}
+		case "l", "L":
+			if args := r.popN(2); args != nil {
 				x := toFloat(args[0])
 				y := toFloat(args[1])
 				d.Lineto(x, y)
-			case "y", "Y":
-				args := toFloatSlice(token.PopN(4))
+				points = append(points, [2]float64{x, y})
+			}
+		case "y", "Y":
+			if args := toFloatSlice(r.popN(4)); args != nil {
 				d.Curveto1(args[0], args[1], args[2], args[3])
-			case "v", "V":
-				args := toFloatSlice(token.PopN(4))
+				points = append(points, [2]float64{args[2], args[3]})
+			}
+		case "v", "V":
+			if args := toFloatSlice(r.popN(4)); args != nil {
 				d.Curveto2(args[0], args[1], args[2], args[3])
-			case "c", "C":
-				args := toFloatSlice(token.PopN(6))
+				points = append(points, [2]float64{args[2], args[3]})
+			}
+		case "c", "C":
+			if args := toFloatSlice(r.popN(6)); args != nil {
 				d.Curveto(args[0], args[1], args[2], args[3], args[4], args[5])
-			case "g": // set fill tint
-				if tint := token.Pop(); tint != "0" {
-					log.Println("todo: NotImplement: g")
-				}
-			case "G": // set stroke tint
-				if tint := token.Pop(); tint != "0" {
-					log.Println("todo: NotImplement: g")
-				}
-			case "k": // fill setcmykcolor
-				args := KArgs(token.PopAll())
-				d.SetCMYK(AI_Fill, args.CMYK())
-			case "K": // stroke setcmykcolor
-				args := KArgs(token.PopAll())
-				d.SetCMYK(AI_Stroke, args.CMYK())
-			case "x": // custom fill
-				args := XArgs(token.PopAll())
-				d.SetCMYK(AI_Fill, args.CMYK())
-			case "X":
-				args := XArgs(token.PopAll())
-				d.SetCMYK(AI_Stroke, args.CMYK())
-			case "Xy": // set opacity
-				args := XYArgs(token.PopN(5))
-				d.SetOpacity(args)
-			case "Xa":
-				args := XAArgs(token.PopAll())
-				d.SetRGB(AI_Fill, args.RGB())
-			case "XA":
-				args := XAArgs(token.PopAll())
-				d.SetRGB(AI_Stroke, args.RGB())
-			case "Xk":
-				args := XKArgs(token.PopAll())
-				if args.colorSpace == 1 {
-					d.SetRGB(AI_Fill, args.RGB())
-				} else {
-					d.SetCMYK(AI_Fill, args.CMYK())
-				}
-			case "XK":
-				args := XKArgs(token.PopAll())
-				if args.colorSpace == 1 {
-					d.SetRGB(AI_Stroke, args.RGB())
-				} else {
-					d.SetCMYK(AI_Stroke, args.CMYK())
-				}
-			case "Xx": // custom fill color
-				args := XXArgs(token.PopAll())
-				if args.colorSpace == 1 {
-					d.SetRGB(AI_Fill, args.RGB())
-				} else {
-					d.SetCMYK(AI_Fill, args.CMYK())
-				}
-			case "XX": // custom stroke color
-				args := XXArgs(token.PopAll())
-				if args.colorSpace == 1 {
-					d.SetRGB(AI_Stroke, args.RGB())
-				} else {
-					d.SetCMYK(AI_Stroke, args.CMYK())
-				}
-			case "XR": // fill rule
-			case "Xw": // 0--visible; 1--invisible
-				// args := token.Pop()
-			case "XW": // 6 () XW; 9 () XW;
-				args := token.PopN(2)
-				if args[0] == "6" {
-					d.SetGroupAttr()
-				}
-			case "XG":
-				args := token.PopN(2)
-				if args[0] != "()" {
-					log.Println("XG NotImplement:", args)
+				points = append(points, [2]float64{args[4], args[5]})
+			}
+		case "g": // fill setgray
+			if args := r.popN(1); args != nil {
+				r.setColor(d, AI_Fill, GArgs(args))
+			}
+		case "G": // stroke setgray
+			if args := r.popN(1); args != nil {
+				r.setColor(d, AI_Stroke, GArgs(args))
+			}
+		case "k": // fill setcmykcolor
+			if args := r.popN(4); args != nil {
+				r.setColor(d, AI_Fill, KArgs(args))
+			}
+		case "K": // stroke setcmykcolor
+			if args := r.popN(4); args != nil {
+				r.setColor(d, AI_Stroke, KArgs(args))
+			}
+		case "x": // custom fill
+			if args := r.popN(6); args != nil {
+				r.setColor(d, AI_Fill, XArgs(args))
+			}
+		case "X":
+			if args := r.popN(6); args != nil {
+				r.setColor(d, AI_Stroke, XArgs(args))
+			}
+		case "Xy": // set opacity
+			r.gs.Opacity = toFloat(XYArgs(token.PopN(5)))
+			d.SetOpacity(r.gs.Opacity)
+		case "Xa":
+			r.setColor(d, AI_Fill, XAArgs(token.PopAll()))
+		case "XA":
+			r.setColor(d, AI_Stroke, XAArgs(token.PopAll()))
+		case "Xk":
+			if args := r.popAll(7); args != nil {
+				r.setColor(d, AI_Fill, XKArgs(args))
+			}
+		case "XK":
+			if args := r.popAll(7); args != nil {
+				r.setColor(d, AI_Stroke, XKArgs(args))
+			}
+		case "Xx": // custom fill color
+			if args := r.popAll(6); args != nil {
+				r.setColor(d, AI_Fill, XXArgs(args))
+			}
+		case "XX": // custom stroke color
+			if args := r.popAll(6); args != nil {
+				r.setColor(d, AI_Stroke, XXArgs(args))
+			}
+		case "XR": // fill rule, 0-non-zero; 1-even-odd
+			r.gs.EvenOdd = token.Pop() == "1"
+		case "Xw": // 0--visible; 1--invisible
+			// args := token.Pop()
+		case "XW": // 6 () XW; 9 () XW;
+			if args := r.popN(2); args != nil && args[0] == "6" {
+				d.SetGroupAttr()
+			}
+		case "Xh": // begin placed art
+			placed = XhArgs(token.PopAll())
+		case "XG": // (path) embedded XG
+			args := token.PopN(2)
+			if placed != nil && len(args) == 2 {
+				placed.Path = toString(args[0])
+				placed.Embedded = args[1] == "1"
+			}
+		case "XH": // end placed art
+			if placed != nil && len(placed.Path) > 0 {
+				placed.Href = placed.Path
+				if r.resolveLink != nil {
+					placed.Href = r.resolveLink(placed.Path)
 				}
-			case "Bb": // begin gradient instance
-				r.beginGradient(d)
-			case "XI":
-				r.readRasterData()
+				d.PlacedArt(placed)
 			}
+			placed = nil
+		case "Bb": // begin gradient instance
+			r.beginGradient(d)
+			points = points[:0]
+		case "XI":
+			r.skipLine()
+			r.readRasterData()
 		}
+
+		if isPaintOp(op) {
+			points = points[:0]
+		}
+		token.Reset()
+	}
+}
+
+// transformPoints maps points to the default coordinates
+func (r *AIReader) transformPoints(points [][2]float64) [][2]float64 {
+	if r.gs.CTM.IsIdentity() {
+		return points
 	}
+
+	res := make([][2]float64, len(points))
+	for i, p := range points {
+		res[i][0], res[i][1] = r.gs.CTM.Apply(p[0], p[1])
+	}
+	return res
 }
 
-func (r *AIReader) beginLayer(d Drawer, args []string) {
+func (r *AIReader) setColor(d DrawerV2, op PathOp, args *ColorArgs) {
+	c := args.Color()
+	r.gs.setColor(op, c)
+	d.SetColor(op, c)
+}
+
+func (r *AIReader) beginLayer(d DrawerV2, args []string) {
 	layer := AILayer{
 		Visible:           args[0] == "1",
 		Preview:           args[1] == "1",
@@ -349,25 +576,38 @@ func (r *AIReader) beginLayer(d Drawer, args []string) {
 		layer.RGB[i] = toUint8(args[colorIndex+i])
 	}
 
+	r.setLayerName(layer.Name)
+	r.layers = append(r.layers, layerMark{ctm: r.gs.CTM, transforms: r.gs.transforms})
 	d.BeginLayer(&layer)
 }
 
+// skipLine skips the rest of the current line
+func (r *AIReader) skipLine() {
+	if _, err := r.lexer.ReadLine(); err != nil && err != io.EOF {
+		r.fail(err)
+	}
+}
+
 func (r *AIReader) readRasterData() []byte {
 	buf := bytes.NewBuffer(nil)
 
 	var enddata1 = []byte("%%EndData")
 	var enddata2 = []byte("%_%%EndData")
-	for {
-		ch, err := r.ReadByte()
+	for r.err == nil {
+		// read through the lexer, which counts the lines of the data
+		ch, err := r.lexer.readByte()
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
-			panic(err)
+			r.fail(err)
+			break
 		}
 
 		buf.WriteByte(ch)
+		r.checkRaster(1)
+		r.reportProgress(false)
 
 		if b := buf.Bytes(); bytes.HasSuffix(b, enddata1) {
 			if bytes.HasSuffix(b, enddata2) {
@@ -381,11 +621,10 @@ func (r *AIReader) readRasterData() []byte {
 	return nil
 }
 
-func (r *AIReader) defGradient(d Drawer) {
-	var token lineToken
-	token.parse(r.Bytes())
-	args := token.PopAll()
-	if !(len(args) == 4 && args[3] == "Bd") {
+func (r *AIReader) defGradient(d DrawerV2) {
+	// (name) type ncolors Bd
+	args := r.operands.PopAll()
+	if len(args) != 3 {
 		log.Println("invalid Bd arguments:", args)
 		return
 	}
@@ -395,111 +634,162 @@ func (r *AIReader) defGradient(d Drawer) {
 		nColors:      toInt(args[2]),
 	}
 
-	for r.readLine() {
-		line := r.Bytes()
-
-		if line[0] == '%' {
+	token := &r.operands
+	for op, ok := r.nextOp(); ok; op, ok = r.nextOp() {
+		if r.token.Kind == TokenComment {
 			// skip comment
 			continue
 		}
 
-		token.parse(line)
-		for token.len > 0 {
-			op := token.Pop()
-			switch op {
-			case "BD":
-				d.DefGradient(gradient)
-				return
-			case "%_Bs", "%_BS":
-				if args := BSArgs(token.PopAll()); args != nil {
-					gradient.AddColor(args)
-				}
+		switch op {
+		case "BD":
+			d.DefGradient(gradient)
+			return
+		case "Bs", "BS": // %_Bs
+			if args := BSArgs(r.popAll(3)); args != nil {
+				gradient.AddColor(args)
 			}
 		}
+		token.Reset()
 	}
 }
 
-func (r *AIReader) beginGradient(d Drawer) {
-	var gradient Gradient
+func (r *AIReader) beginGradient(d DrawerV2) {
+	gradient := Gradient{Matrix: Identity()}
+	set := false // the gradient is sent before the first paint of the block
 
-	var token lineToken
-	for r.readLine() {
-		line := r.Bytes()
-
-		if line[0] == '%' {
+	token := &r.operands
+	token.Reset()
+	for op, ok := r.nextOp(); ok; op, ok = r.nextOp() {
+		if r.token.Kind == TokenComment {
 			// skip comment
 			continue
 		}
 
-		token.parse(line)
-		for token.len > 0 {
-			op := token.Pop()
-			switch op {
-			case "f":
-				// fill path
-				d.ClosePath()
-				d.PathRender(AI_Fill)
-			case "Bc": // define gradient instance cap
-			case "Bg":
-				args := token.PopAll()
-				gradient.Flag = toInt8(args[0])
-			case "Bh": // xHilight yHilight angle length Bh
-			case "Bm": // set gradient matrix
-			case "Xm": // set linear gradient matrix
-			case "BB":
+		switch op {
+		case "f":
+			// fill path
+			d.ClosePath()
+			if !set {
 				d.SetGradient(&gradient)
-				args := token.Pop()
-				if args == "0" {
-					// no action
-				} else if args == "1" {
-					// stroke path
-					d.PathRender(AI_Stroke)
-				} else {
-					// close and stroke path
-					d.ClosePath()
-					d.PathRender(AI_Stroke)
-				}
+				set = true
+			}
+			d.PathRender(AI_Fill, &r.gs)
+		case "Bc": // define gradient instance cap
+		case "Bg":
+			if args := r.popAll(1); args != nil {
+				gradient.Flag = toInt8(args[0])
+			}
+		case "Bh": // xHilight yHilight angle length Bh
+		case "Bm": // a b c d tx ty Bm, set gradient matrix
+			gradient.Matrix = MatrixArgs(token.PopAll())
+		case "Xm": // set linear gradient matrix
+		case "BB":
+			args := token.Pop()
+			token.Reset()
+			if args == "0" {
+				// no action
 				return
 			}
+
+			if !set {
+				d.SetGradient(&gradient)
+			}
+			if args == "1" {
+				// stroke path
+				d.PathRender(AI_Stroke, &r.gs)
+			} else {
+				// close and stroke path
+				d.ClosePath()
+				d.PathRender(AI_Stroke, &r.gs)
+			}
+			return
 		}
+		token.Reset()
 	}
 }
 
-func (r *AIReader) beginRaster(d Drawer) {
-	var token lineToken
-
-	XI := []byte("XI")
-	var xiargs []byte
-	for r.readLine() {
-		line := r.Bytes()
-
-		if bytes.Equal(AI5_EndRaster, line) {
-			break
+func (r *AIReader) beginRaster(d DrawerV2) {
+	token := &r.operands
+	token.Reset()
+	for op, ok := r.nextOp(); ok; op, ok = r.nextOp() {
+		if r.token.Kind == TokenComment {
+			if r.isComment(AI5_EndRaster) {
+				break
+			}
+			continue
 		}
 
-		if line[0] == '[' && line[len(line)-1] != 'h' {
-			xiargs = make([]byte, len(line))
-			copy(xiargs, line)
+		switch op {
+		case "Xh":
+		case "XF":
+		case "XG":
+		case "XI":
+			r.skipLine()
+			data := r.readRasterData()
+			if obj := XIArgs(token.PopAll()); obj != nil {
+				obj.RawData = data
+				d.SetRaster(obj)
+			}
 		}
+		token.Reset()
+	}
+}
 
-		if bytes.Equal(line, XI) && len(xiargs) > 0 {
-			line = append(xiargs, ' ', 'X', 'I')
+func (r *AIReader) beginMesh(d DrawerV2) {
+	var mesh Mesh
+	var node *MeshNode
+
+	token := &r.operands
+	token.Reset()
+	for op, ok := r.nextOp(); ok; op, ok = r.nextOp() {
+		if r.token.Kind == TokenComment {
+			if r.isComment(AI8_EndMesh) {
+				break
+			}
+			continue // skip comment
 		}
 
-		token.parse(line)
-		for token.len > 0 {
-			op := token.Pop()
-			switch op {
-			case "Xh":
-			case "XF":
-			case "XG":
-			case "XI":
-				data := r.readRasterData()
-				if obj := XIArgs(token.PopAll()); obj != nil {
-					obj.RawData = data
-					d.SetRaster(obj)
+		switch op {
+		case "Xv": // mesh version
+			mesh.Version = toInt(token.Pop())
+		case "Xd": // rows cols Xd
+			if args := r.popN(2); args != nil {
+				rows, cols := toInt(args[0]), toInt(args[1])
+				if rows < 0 || cols < 0 || rows >= maxMeshNodes || cols >= maxMeshNodes || (rows+1)*(cols+1) > maxMeshNodes {
+					r.fail(fmt.Errorf("%w: line %d: invalid mesh size %dx%d", ErrSyntax, r.token.Line, rows, cols))
+					break
+				}
+				if max := r.limits.MaxNodes; max > 0 && (rows+1)*(cols+1) > max {
+					r.fail(fmt.Errorf("%w: mesh of more than %d nodes", ErrLimitExceeded, max))
+					break
 				}
+				mesh.init(rows, cols)
+			}
+		case "Xn": // row col Xn
+			if args := r.popN(2); args != nil {
+				node = mesh.Node(toInt(args[0]), toInt(args[1]))
+			}
+		case "Xp": // x y Xp
+			if args := toFloatSlice(r.popN(2)); args != nil && node != nil {
+				node.Point = [2]float64{args[0], args[1]}
+			}
+		case "Xh": // up right down left Xh
+			if args := toFloatSlice(r.popN(8)); args != nil && node != nil {
+				for i := range node.Handles {
+					node.Handles[i] = [2]float64{args[2*i], args[2*i+1]}
+				}
+			}
+		case "Xc": // color of current node
+			args := token.PopAll()
+			if node != nil {
+				node.Color = XcArgs(args)
 			}
 		}
+		token.Reset()
+	}
+
+	if len(mesh.Nodes) > 0 {
+		d.Mesh(&mesh)
 	}
 }
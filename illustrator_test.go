@@ -0,0 +1,110 @@
+package illustrator
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// drawText draws src with a RecordingDrawer, one call per line
+func drawText(t *testing.T, src io.Reader) []byte {
+	t.Helper()
+
+	r, err := NewAIReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := NewRecordingDrawer(r.Line)
+	if err := r.DrawV2(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := rec.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDrawGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.ps")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".ps")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := drawText(t, bytes.NewReader(src))
+			golden := strings.TrimSuffix(file, ".ps") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from %s, run go test -update\ngot:\n%s", file, golden, got)
+			}
+		})
+	}
+}
+
+func TestDrawErrors(t *testing.T) {
+	const layer = "%%EndComments\n%AI5_BeginLayer\n1 1 1 1 0 0 -1 79 128 255 Lb\n"
+	tests := []struct {
+		name string
+		src  string
+		err  error
+	}{
+		{"moveto", layer + "10 m\n", ErrSyntax},
+		{"lineto", layer + "0 0 m L\n", ErrSyntax},
+		{"curveto", layer + "0 0 m 1 2 3 c\n", ErrSyntax},
+		{"curveto1", layer + "0 0 m 1 2 3 y\n", ErrSyntax},
+		{"curveto2", layer + "0 0 m 1 v\n", ErrSyntax},
+		{"gray", layer + "g\n", ErrSyntax},
+		{"cmyk", layer + "0 0 1 K\n", ErrSyntax},
+		{"custom color", layer + "0 0 0 1 (spot) x\n", ErrSyntax},
+		{"custom rgb", layer + "1 0 (spot) 1 1 Xk\n", ErrSyntax},
+		{"group attr", layer + "XW\n", ErrSyntax},
+		{"layer", "%%EndComments\n%AI5_BeginLayer\n1 1 1 Lb\n", ErrSyntax},
+		{"mesh size", layer + "%AI8_BeginMesh\n1 Xd\n%AI8_EndMesh\n", ErrSyntax},
+		{"negative mesh size", layer + "%AI8_BeginMesh\n-1 2 Xd\n%AI8_EndMesh\n", ErrSyntax},
+		{"huge mesh size", layer + "%AI8_BeginMesh\n100000 100000 Xd\n%AI8_EndMesh\n", ErrSyntax},
+		{"overflowing mesh size", layer + "%AI8_BeginMesh\n4611686018427387904 4611686018427387904 Xd\n%AI8_EndMesh\n", ErrSyntax},
+		{"mesh node", layer + "%AI8_BeginMesh\n1 1 Xd\n0 Xn\n%AI8_EndMesh\n", ErrSyntax},
+		{"mesh handles", layer + "%AI8_BeginMesh\n1 1 Xd\n0 0 Xn\n0 0 Xh\n%AI8_EndMesh\n", ErrSyntax},
+		{"unterminated string", layer + "(Layer Ln\n", io.ErrUnexpectedEOF},
+		{"unterminated hex string", layer + "<0A0B\n", io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewAIReader(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := r.DrawV2(BaseDrawer{}); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package illustrator
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+var ErrCorruptPrivateData = errors.New("illustrator: corrupt private data")
+
+// privateDecoder decompresses the private data, errors of the decoder are
+// reported with the position in the compressed data
+type privateDecoder struct {
+	io.ReadCloser
+	name   string
+	in     *countingReader
+	length int64 // compressed bytes
+	out    int64 // decompressed bytes
+}
+
+func newPrivateDecoder(enc *Encoding, r io.Reader, length int64) (io.ReadCloser, error) {
+	d := &privateDecoder{name: "none", in: &countingReader{r: r}, length: length}
+	if enc == nil {
+		d.ReadCloser = io.NopCloser(d.in)
+		return d, nil
+	}
+
+	d.name = enc.Name
+	handle := enc.New()
+	if h, ok := handle.(StreamHandle); ok {
+		rd, err := h.NewReader(d.in)
+		if err != nil {
+			return nil, d.wrap(err)
+		}
+		d.ReadCloser = rd
+		return d, nil
+	}
+
+	// encodings without a streaming decoder are decompressed in memory,
+	// the position is then in the decompressed data
+	data, err := io.ReadAll(d.in)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := handle.Write(data); err != nil {
+		return nil, err
+	}
+	data, err = handle.Decompress()
+	if err != nil {
+		return nil, d.wrap(err)
+	}
+	d.in = &countingReader{r: bytes.NewReader(data)}
+	d.length = int64(len(data))
+	d.ReadCloser = io.NopCloser(d.in)
+	return d, nil
+}
+
+// position returns the bytes consumed of the compressed data and its length
+func (d *privateDecoder) position() (int64, int64) {
+	return d.in.n, d.length
+}
+
+func (d *privateDecoder) Read(p []byte) (int, error) {
+	n, err := d.ReadCloser.Read(p)
+	d.out += int64(n)
+	if err != nil && err != io.EOF {
+		err = d.wrap(err)
+	}
+	return n, err
+}
+
+func (d *privateDecoder) wrap(err error) error {
+	var corrupt flate.CorruptInputError
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: %s data truncated, it ends after %d of %d compressed bytes, %d bytes decompressed",
+			ErrCorruptPrivateData, d.name, d.in.n, d.length, d.out)
+	case errors.As(err, &corrupt):
+		return fmt.Errorf("%w: %s data corrupted at byte %d of the deflate stream, %d bytes decompressed",
+			ErrCorruptPrivateData, d.name, int64(corrupt), d.out)
+	case errors.Is(err, zlib.ErrHeader):
+		return fmt.Errorf("%w: invalid zlib header", ErrCorruptPrivateData)
+	case errors.Is(err, zlib.ErrChecksum):
+		return fmt.Errorf("%w: zlib checksum mismatch, %d bytes decompressed", ErrCorruptPrivateData, d.out)
+	case errors.Is(err, zstd.ErrMagicMismatch):
+		return fmt.Errorf("%w: invalid zstd frame header", ErrCorruptPrivateData)
+	case errors.Is(err, zstd.ErrCRCMismatch):
+		return fmt.Errorf("%w: zstd checksum mismatch, %d bytes decompressed", ErrCorruptPrivateData, d.out)
+	}
+	return fmt.Errorf("%w: %s decompression failed after %d of %d compressed bytes, %d bytes decompressed: %v",
+		ErrCorruptPrivateData, d.name, d.in.n, d.length, d.out, err)
+}
+
+// ChunkInfo describes an AIPrivateDataN stream
+type ChunkInfo struct {
+	Index    int    // N of AIPrivateDataN
+	Length   int    // bytes of data, after the pdf filters
+	Declared int    // the /Length of the stream, -1 when missing
+	Raw      int    // bytes of the stream in the file
+	Filter   string // pdf filters, empty when none
+	Err      error  // error of the pdf filters
+}
+
+// Report is the diagnosis of the private data
+type Report struct {
+	Page             int
+	NumBlock         int // declared /NumBlock, -1 when missing
+	Chunks           []ChunkInfo
+	Missing          []int  // missing N up to the largest N
+	Encoding         string // zlib, zstd, none or unknown
+	HeaderChunk      int    // N of the stream starting with the encoding header, 0 when none
+	Skipped          []int  // N of the streams before the encoding header, they are not decoded
+	CompressedSize   int64
+	Size             int64  // decompressed bytes
+	Checksum         string // sha256 of the decompressed data
+	ContainerVersion int
+	CreatorVersion   int
+	RoundTripVersion int
+	Creator          string // %%Creator of the private data
+	AIVersion        string // %%AI8_CreatorVersion of the private data
+	Err              error  // decompression error
+	Problems         []string
+}
+
+// OK reports whether no problem was found
+func (rep *Report) OK() bool {
+	return len(rep.Problems) == 0
+}
+
+func (rep *Report) problem(format string, args ...interface{}) {
+	rep.Problems = append(rep.Problems, fmt.Sprintf(format, args...))
+}
+
+func (rep *Report) String() string {
+	var w strings.Builder
+	fmt.Fprintf(&w, "page: %d\n", rep.Page)
+	fmt.Fprintf(&w, "versions: container %d, creator %d, round trip %d\n",
+		rep.ContainerVersion, rep.CreatorVersion, rep.RoundTripVersion)
+	if len(rep.Creator) > 0 {
+		fmt.Fprintf(&w, "creator: %s\n", rep.Creator)
+	}
+	if len(rep.AIVersion) > 0 {
+		fmt.Fprintf(&w, "ai version: %s\n", rep.AIVersion)
+	}
+	fmt.Fprintf(&w, "encoding: %s\n", rep.Encoding)
+	fmt.Fprintf(&w, "chunks: %d, NumBlock %d\n", len(rep.Chunks), rep.NumBlock)
+	for _, c := range rep.Chunks {
+		fmt.Fprintf(&w, "  AIPrivateData%d: %d bytes, declared %d, raw %d", c.Index, c.Length, c.Declared, c.Raw)
+		if len(c.Filter) > 0 {
+			fmt.Fprintf(&w, ", filter %s", c.Filter)
+		}
+		w.WriteByte('\n')
+	}
+	fmt.Fprintf(&w, "compressed: %d bytes\n", rep.CompressedSize)
+	fmt.Fprintf(&w, "decompressed: %d bytes\n", rep.Size)
+	if len(rep.Checksum) > 0 {
+		fmt.Fprintf(&w, "sha256: %s\n", rep.Checksum)
+	}
+	for _, p := range rep.Problems {
+		fmt.Fprintf(&w, "problem: %s\n", p)
+	}
+	return w.String()
+}
+
+// how many bytes of the private data are searched for the header comments
+const inspectHeadSize = 16 * 1024
+
+// Inspect checks the AIPrivateDataN streams and decompresses them, the
+// problems found are listed in the report
+func (r *Reader) Inspect() (*Report, error) {
+	dict := r.getPrivate()
+	if dict == nil {
+		return nil, ErrNoPrivateData
+	}
+
+	rep := &Report{Page: r.pageNum, NumBlock: -1}
+	if v, ok := core.GetIntVal(dict.Get("NumBlock")); ok {
+		rep.NumBlock = v
+	}
+	rep.ContainerVersion, _ = core.GetIntVal(dict.Get("ContainerVersion"))
+	rep.CreatorVersion, _ = core.GetIntVal(dict.Get("CreatorVersion"))
+	rep.RoundTripVersion, _ = core.GetIntVal(dict.Get("RoundTripVersion"))
+
+	p := walkPrivateData(dict)
+	if len(p.chunks) == 0 && len(p.missing) == 0 {
+		return nil, ErrNoPrivateData
+	}
+
+	rep.Missing = p.missing
+	for _, i := range p.missing {
+		rep.problem("AIPrivateData%d is missing", i)
+	}
+
+	for _, pc := range p.chunks {
+		c := ChunkInfo{Index: pc.index, Declared: -1, Raw: len(pc.stream.Stream), Length: len(pc.data), Err: pc.err}
+		if v, ok := core.GetIntVal(pc.stream.Get("Length")); ok {
+			c.Declared = v
+		}
+		if filter := pc.stream.Get("Filter"); filter != nil {
+			c.Filter = filter.WriteString()
+		}
+
+		if pc.err != nil {
+			rep.problem("AIPrivateData%d: %v", pc.index, pc.err)
+		}
+		if c.Declared >= 0 && c.Declared != c.Raw {
+			rep.problem("AIPrivateData%d: declared length %d, actual %d", pc.index, c.Declared, c.Raw)
+		}
+		rep.Chunks = append(rep.Chunks, c)
+	}
+
+	if rep.NumBlock < 0 {
+		rep.problem("NumBlock is missing")
+	} else if max := p.max(); rep.NumBlock != max {
+		rep.problem("NumBlock is %d, the largest chunk is AIPrivateData%d", rep.NumBlock, max)
+	}
+
+	if len(p.chunks) == 0 {
+		return rep, nil
+	}
+
+	compressed, length := p.compressed()
+	rep.CompressedSize = length
+	switch {
+	case p.encErr != nil:
+		rep.Encoding = "unknown"
+		rep.Err = p.encErr
+		rep.problem("unknown encoding, the data starts with %q", head(p.chunks[0].data, 20))
+		return rep, nil
+	case p.enc == nil:
+		rep.Encoding = "none"
+	default:
+		rep.Encoding = p.enc.Name
+		rep.HeaderChunk = p.chunks[p.start].index
+	}
+
+	rep.Skipped = p.skipped()
+	for _, i := range rep.Skipped {
+		rep.problem("AIPrivateData%d is before the encoding header in AIPrivateData%d, it is skipped", i, rep.HeaderChunk)
+	}
+
+	rd, err := newPrivateDecoder(p.enc, compressed, rep.CompressedSize)
+	if err == nil {
+		hash := sha256.New()
+		var headBuf bytes.Buffer
+		headWriter := &limitedWriter{w: &headBuf, n: inspectHeadSize}
+		rep.Size, err = io.Copy(io.MultiWriter(hash, headWriter), rd)
+		rd.Close()
+		rep.Checksum = hex.EncodeToString(hash.Sum(nil))
+		rep.Creator, rep.AIVersion = headerComments(headBuf.Bytes())
+	}
+	if err != nil {
+		rep.Err = err
+		rep.Checksum = ""
+		rep.problem("%v", err)
+	}
+	return rep, nil
+}
+
+func head(data []byte, n int) []byte {
+	if len(data) > n {
+		return data[:n]
+	}
+	return data
+}
+
+// limitedWriter keeps the first n bytes written
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n > 0 {
+		b := head(p, l.n)
+		l.w.Write(b)
+		l.n -= len(b)
+	}
+	return len(p), nil
+}
+
+// headerComments returns %%Creator and %%AI8_CreatorVersion of the header
+func headerComments(data []byte) (creator, version string) {
+	// old files end lines with \r
+	sc := bufio.NewScanner(bytes.NewReader(bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))))
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "%%Creator:"):
+			creator = strings.TrimSpace(line[len("%%Creator:"):])
+		case strings.HasPrefix(line, "%%AI8_CreatorVersion:"):
+			version = strings.TrimSpace(line[len("%%AI8_CreatorVersion:"):])
+		case strings.HasPrefix(line, "%%EndComments"):
+			return
+		}
+	}
+	return
+}
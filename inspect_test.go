@@ -0,0 +1,162 @@
+package illustrator
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// corrupt returns a copy of data with fn applied
+func corrupt(data []byte, fn func([]byte) []byte) []byte {
+	return fn(append([]byte(nil), data...))
+}
+
+func TestCorruptPrivateData(t *testing.T) {
+	// enough postscript for the compressed data to span several chunks
+	ps := []byte(strings.Repeat(samplePS, 20))
+	zlibData := encodePS(t, "zlib", ps)
+	zstdData := encodePS(t, "zstd", ps)
+	zlibHeader := len("%AI12_CompressedData")
+	zstdHeader := len("%AI24_ZStandard_Data")
+
+	tests := []struct {
+		name   string
+		chunks [][]byte
+		want   string // message of the error and of the report
+	}{
+		{
+			name:   "zlib truncated",
+			chunks: splitData(zlibData[:len(zlibData)/2], 2),
+			want:   "zlib data truncated",
+		},
+		{
+			name: "zlib corrupted",
+			chunks: [][]byte{corrupt(zlibData, func(b []byte) []byte {
+				b[zlibHeader+2] = 0x07 // final block of the reserved type
+				return b
+			})},
+			want: "zlib data corrupted at byte 1",
+		},
+		{
+			name: "zlib header",
+			chunks: [][]byte{corrupt(zlibData, func(b []byte) []byte {
+				b[zlibHeader], b[zlibHeader+1] = 0, 0
+				return b
+			})},
+			want: "invalid zlib header",
+		},
+		{
+			name: "zlib checksum",
+			chunks: [][]byte{corrupt(zlibData, func(b []byte) []byte {
+				b[len(b)-1] ^= 0xff
+				return b
+			})},
+			want: "zlib checksum mismatch",
+		},
+		{
+			name:   "zstd truncated",
+			chunks: splitData(zstdData[:len(zstdData)/2], 2),
+			want:   "zstd data truncated",
+		},
+		{
+			name: "zstd header",
+			chunks: [][]byte{corrupt(zstdData, func(b []byte) []byte {
+				copy(b[zstdHeader:], "\x00\x00\x00\x00")
+				return b
+			})},
+			want: "invalid zstd frame header",
+		},
+		{
+			name: "zstd checksum",
+			chunks: [][]byte{corrupt(zstdData, func(b []byte) []byte {
+				b[len(b)-1] ^= 0xff
+				return b
+			})},
+			want: "zstd checksum mismatch",
+		},
+		{
+			name:   "missing chunk",
+			chunks: [][]byte{zlibData[:100], nil, zlibData[100:]},
+			want:   "AIPrivateData2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewBytesReader(aiPDF(tt.chunks, false))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = r.GetAIPrivateData()
+			if !errors.Is(err, ErrCorruptPrivateData) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+
+			rep, err := r.Inspect()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rep.OK() || !strings.Contains(strings.Join(rep.Problems, "\n"), tt.want) {
+				t.Errorf("report has no %q:\n%s", tt.want, rep)
+			}
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	data := encodePS(t, "zstd", []byte(samplePS))
+	r, err := NewBytesReader(aiPDF(splitData(data, 3), true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := r.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.OK() {
+		t.Errorf("problems:\n%s", rep)
+	}
+	if rep.Encoding != "zstd" || len(rep.Chunks) != 3 || rep.NumBlock != 3 || rep.HeaderChunk != 1 {
+		t.Errorf("report:\n%s", rep)
+	}
+	if rep.Size != int64(len(samplePS)) || rep.CompressedSize != int64(len(data)-len("%AI24_ZStandard_Data")) {
+		t.Errorf("size %d compressed %d", rep.Size, rep.CompressedSize)
+	}
+	if rep.Creator != "Adobe Illustrator(R) 24.0" || rep.AIVersion != "24.0.0" {
+		t.Errorf("creator %q version %q", rep.Creator, rep.AIVersion)
+	}
+
+	// the private data after the report
+	got, err := r.GetAIPrivateData()
+	if err != nil || !bytes.Equal(got, []byte(samplePS)) {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
+
+func TestLeadingChunks(t *testing.T) {
+	data := encodePS(t, "zlib", []byte(samplePS))
+	chunks := append([][]byte{[]byte("not private data")}, splitData(data, 2)...)
+	r, err := NewBytesReader(aiPDF(chunks, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.GetAIPrivateData()
+	if err != nil || !bytes.Equal(got, []byte(samplePS)) {
+		t.Errorf("got %q, %v", got, err)
+	}
+
+	rep, err := r.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Err != nil || rep.Encoding != "zlib" || rep.HeaderChunk != 2 || len(rep.Skipped) != 1 || rep.Skipped[0] != 1 {
+		t.Errorf("report:\n%s", rep)
+	}
+	if rep.Size != int64(len(samplePS)) {
+		t.Errorf("size %d, want %d", rep.Size, len(samplePS))
+	}
+}
@@ -9,12 +9,14 @@ type ColorArgs struct {
 	rgb  [3]float64
 
 	gray float64 //
+	name string  // custom color name
 
 	tint       float64 // between 0-1
-	colorSpace int8    // 0-CMYK, 1-RGB
+	colorSpace int8    // 0-CMYK, 1-RGB, 2-Gray
 }
 
 func (args *ColorArgs) SetTint(tint float64) {
+	args.tint = tint
 	if tint < 1e-6 || tint > 1.0 {
 		return
 	}
@@ -34,6 +36,11 @@ func (args *ColorArgs) SetTint(tint float64) {
 	}
 }
 
+// IsRGB reports whether the color is in rgb color space, otherwise cmyk
+func (args *ColorArgs) IsRGB() bool {
+	return args.colorSpace == 1
+}
+
 func (args *ColorArgs) RGB() [3]uint8 {
 	return [3]uint8{
 		uint8(math.Round(args.rgb[0] * 255)),
@@ -46,8 +53,54 @@ func (args *ColorArgs) CMYK() [4]float64 {
 	return args.cmyk
 }
 
+func (args *ColorArgs) CMYK2RGB() [3]uint8 {
+	c, m := args.cmyk[0], args.cmyk[1]
+	y, k := args.cmyk[2], args.cmyk[3]
+	return [3]uint8{
+		uint8(math.Round((1 - c) * (1 - k) * 255)),
+		uint8(math.Round((1 - m) * (1 - k) * 255)),
+		uint8(math.Round((1 - y) * (1 - k) * 255)),
+	}
+}
+
+// Color returns the typed color, named colors are spot colors
+func (args *ColorArgs) Color() Color {
+	var c Color
+	switch args.colorSpace {
+	case 1:
+		c = RGBColor(args.rgb[0], args.rgb[1], args.rgb[2])
+	case 2:
+		c = GrayColor(args.gray)
+	default:
+		c = CMYKColor(args.cmyk[0], args.cmyk[1], args.cmyk[2], args.cmyk[3])
+	}
+
+	if len(args.name) > 0 {
+		c.Alt = c.Space
+		c.Space = ColorSpot
+		c.Name = args.name
+		c.Tint = args.tint
+	}
+	return c
+}
+
+func GArgs(vals []string) *ColorArgs {
+	// gray g
+	var args ColorArgs
+	args.colorSpace = 2 // set gray color space
+	if len(vals) == 1 {
+		args.gray = toFloat(vals[0])
+		args.cmyk[3] = 1 - args.gray
+	} else {
+		panic("invalid g arguments")
+	}
+
+	return &args
+}
+
 func XAArgs(vals []string) *ColorArgs {
 	var args ColorArgs
+	args.colorSpace = 1 // set rgb color space
 	if len(vals) == 3 {
 		args.rgb[0] = toFloat(vals[0])
 		args.rgb[1] = toFloat(vals[1])
@@ -121,6 +174,7 @@ func XArgs(vals []string) *ColorArgs {
 		args.cmyk[1] = toFloat(vals[1])
 		args.cmyk[2] = toFloat(vals[2])
 		args.cmyk[3] = toFloat(vals[3])
+		args.name = toString(vals[4])
 		args.SetTint(toFloat(vals[5]))
 	} else {
 		panic("invalid x arguments")
@@ -132,7 +186,7 @@ func XArgs(vals []string) *ColorArgs {
 func XXArgs(vals []string) *ColorArgs {
 	var args ColorArgs
 	args.colorSpace = toInt8(vals[len(vals)-1])
-	if args.colorSpace == 0 {
+	if args.colorSpace == 1 {
 		args.rgb[0] = toFloat(vals[0])
 		args.rgb[1] = toFloat(vals[1])
 		args.rgb[2] = toFloat(vals[2])
@@ -144,6 +198,7 @@ func XXArgs(vals []string) *ColorArgs {
 	}
 
 	// set tint
+	args.name = toString(vals[len(vals)-3])
 	args.SetTint(toFloat(vals[len(vals)-2]))
 	return &args
 }
@@ -0,0 +1,396 @@
+package illustrator
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type TokenKind int8
+
+const (
+	TokenEOF        TokenKind = iota
+	TokenNumber               // 12, -3.5, 16#FF
+	TokenString               // (string)
+	TokenHexString            // <48656C6C6F> or <~87cURD]i~>
+	TokenName                 // /name
+	TokenArrayStart           // [
+	TokenArrayEnd             // ]
+	TokenOperator             // m, Xa, *u, {, <<
+	TokenComment              // %comment, up to the end of line
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenEOF:
+		return "eof"
+	case TokenNumber:
+		return "number"
+	case TokenString:
+		return "string"
+	case TokenHexString:
+		return "hexstring"
+	case TokenName:
+		return "name"
+	case TokenArrayStart:
+		return "arraystart"
+	case TokenArrayEnd:
+		return "arrayend"
+	case TokenOperator:
+		return "operator"
+	case TokenComment:
+		return "comment"
+	}
+	return "unknown"
+}
+
+type Token struct {
+	Kind TokenKind
+	Text string // source text of the token
+	Line int    // line number, starting from 1
+}
+
+func (t Token) Float() float64 {
+	if t.Kind != TokenNumber {
+		return 0
+	}
+
+	if i := strings.IndexByte(t.Text, '#'); i > 0 {
+		base, _ := strconv.Atoi(t.Text[:i])
+		v, _ := strconv.ParseInt(t.Text[i+1:], base, 64)
+		return float64(v)
+	}
+
+	return toFloat(t.Text)
+}
+
+func (t Token) Int() int {
+	return int(t.Float())
+}
+
+// Value returns the decoded value of strings, hex strings and names
+func (t Token) Value() string {
+	switch t.Kind {
+	case TokenString:
+		return toString(t.Text)
+	case TokenHexString:
+		if strings.HasPrefix(t.Text, "<~") {
+			return t.Text // ascii85 is left to the consumer
+		}
+
+		digits := strings.Map(func(r rune) rune {
+			if isSpace(byte(r)) {
+				return -1
+			}
+			return r
+		}, t.Text[1:len(t.Text)-1])
+		if len(digits)%2 == 1 {
+			digits += "0"
+		}
+		v, _ := hex.DecodeString(digits)
+		return string(v)
+	case TokenName:
+		return t.Text[1:]
+	}
+	return t.Text
+}
+
+func isSpace(ch byte) bool {
+	switch ch {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func isDelimiter(ch byte) bool {
+	switch ch {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isNumber(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	switch c := s[0]; {
+	case c >= '0' && c <= '9', c == '+', c == '-', c == '.':
+	default:
+		return false
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+
+	// radix number: base#digits
+	if i := strings.IndexByte(s, '#'); i > 0 {
+		base, err := strconv.Atoi(s[:i])
+		if err != nil || base < 2 || base > 36 {
+			return false
+		}
+		_, err = strconv.ParseInt(s[i+1:], base, 64)
+		return err == nil
+	}
+
+	return false
+}
+
+// Lexer splits postscript source into tokens. It reads the source one byte
+// at a time, so that the underlying reader can be used for binary data,
+// such as raster images, between tokens.
+type Lexer struct {
+	r    *bufio.Reader
+	line int
+	cr   bool // last byte is \r
+
+	buf     []byte
+	lineBuf Buffer
+}
+
+func NewLexer(r io.Reader) *Lexer {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	return &Lexer{r: br, line: 1}
+}
+
+// Line returns the current line number
+func (lx *Lexer) Line() int {
+	return lx.line
+}
+
+func (lx *Lexer) readByte() (byte, error) {
+	ch, err := lx.r.ReadByte()
+	if err != nil {
+		return ch, err
+	}
+
+	switch ch {
+	case '\n':
+		// \r\n is counted at \r
+		if !lx.cr {
+			lx.line++
+		}
+		lx.cr = false
+	case '\r':
+		lx.line++
+		lx.cr = true
+	default:
+		lx.cr = false
+	}
+
+	return ch, nil
+}
+
+func (lx *Lexer) unreadByte(ch byte) {
+	if ch == '\n' || ch == '\r' {
+		lx.line--
+	}
+	lx.cr = false
+	lx.r.UnreadByte()
+}
+
+// ReadLine reads the rest of the current line, without the line ending
+func (lx *Lexer) ReadLine() ([]byte, error) {
+	lx.lineBuf.Reset()
+	for {
+		ch, err := lx.readByte()
+		if err != nil {
+			if err == io.EOF && lx.lineBuf.Len() > 0 {
+				return lx.lineBuf.Bytes(), nil
+			}
+			return lx.lineBuf.Bytes(), err
+		}
+
+		if ch == '\n' {
+			break
+		}
+
+		if ch == '\r' {
+			if next, err := lx.r.Peek(1); err == nil && next[0] == '\n' {
+				lx.readByte()
+			}
+			break
+		}
+
+		lx.lineBuf.WriteByte(ch)
+	}
+
+	return lx.lineBuf.Bytes(), nil
+}
+
+// Next returns the next token, io.EOF at the end of source
+func (lx *Lexer) Next() (Token, error) {
+	var ch byte
+	var err error
+	for {
+		if ch, err = lx.readByte(); err != nil {
+			return Token{Kind: TokenEOF, Line: lx.line}, err
+		}
+
+		if isSpace(ch) {
+			continue
+		}
+
+		if ch == '%' {
+			// %_ hides operands from postscript interpreters, but not from illustrator
+			if next, err := lx.r.Peek(1); err == nil && next[0] == '_' {
+				lx.readByte()
+				continue
+			}
+		}
+		break
+	}
+
+	line := lx.line
+	lx.buf = append(lx.buf[:0], ch)
+	switch ch {
+	case '%':
+		err = lx.readComment()
+		return lx.token(TokenComment, line), err
+	case '(':
+		err = lx.readString()
+		return lx.token(TokenString, line), err
+	case '<':
+		next, _ := lx.r.Peek(1)
+		if len(next) > 0 && next[0] == '<' {
+			lx.readByte()
+			lx.buf = append(lx.buf, '<')
+			return lx.token(TokenOperator, line), nil
+		}
+		err = lx.readHexString()
+		return lx.token(TokenHexString, line), err
+	case '>':
+		next, _ := lx.r.Peek(1)
+		if len(next) > 0 && next[0] == '>' {
+			lx.readByte()
+			lx.buf = append(lx.buf, '>')
+			return lx.token(TokenOperator, line), nil
+		}
+		return lx.token(TokenOperator, line), nil
+	case '[':
+		return lx.token(TokenArrayStart, line), nil
+	case ']':
+		return lx.token(TokenArrayEnd, line), nil
+	case '{', '}':
+		return lx.token(TokenOperator, line), nil
+	case ')':
+		return lx.token(TokenOperator, line), nil // unbalanced
+	case '/':
+		// //name is an immediately evaluated name
+		if next, _ := lx.r.Peek(1); len(next) > 0 && next[0] == '/' {
+			lx.readByte()
+			lx.buf = append(lx.buf, '/')
+		}
+		err = lx.readRegular()
+		return lx.token(TokenName, line), err
+	}
+
+	err = lx.readRegular()
+	if isNumber(string(lx.buf)) {
+		return lx.token(TokenNumber, line), err
+	}
+	return lx.token(TokenOperator, line), err
+}
+
+func (lx *Lexer) token(kind TokenKind, line int) Token {
+	return Token{Kind: kind, Text: string(lx.buf), Line: line}
+}
+
+func (lx *Lexer) readComment() error {
+	for {
+		ch, err := lx.readByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if ch == '\r' || ch == '\n' {
+			lx.unreadByte(ch)
+			return nil
+		}
+		lx.buf = append(lx.buf, ch)
+	}
+}
+
+func (lx *Lexer) readRegular() error {
+	for {
+		ch, err := lx.readByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if isSpace(ch) || isDelimiter(ch) {
+			lx.unreadByte(ch)
+			return nil
+		}
+		lx.buf = append(lx.buf, ch)
+	}
+}
+
+func (lx *Lexer) readString() error {
+	line := lx.line
+	depth := 1
+	for {
+		ch, err := lx.readByte()
+		if err == io.EOF {
+			return fmt.Errorf("line %d: unterminated string: %w", line, io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			return err
+		}
+		lx.buf = append(lx.buf, ch)
+
+		switch ch {
+		case '\\':
+			// keep the escaped character, it is decoded by Token.Value
+			if ch, err = lx.readByte(); err != nil {
+				return fmt.Errorf("line %d: unterminated string: %w", line, io.ErrUnexpectedEOF)
+			}
+			lx.buf = append(lx.buf, ch)
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (lx *Lexer) readHexString() error {
+	line := lx.line
+	end := byte('>')
+	if next, _ := lx.r.Peek(1); len(next) > 0 && next[0] == '~' {
+		end = '~' // ascii85 string <~ ... ~>
+	}
+
+	for {
+		ch, err := lx.readByte()
+		if err == io.EOF {
+			return fmt.Errorf("line %d: unterminated hex string: %w", line, io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			return err
+		}
+		lx.buf = append(lx.buf, ch)
+
+		if ch == '>' && (end == '>' || (len(lx.buf) > 3 && lx.buf[len(lx.buf)-2] == '~')) {
+			return nil
+		}
+	}
+}
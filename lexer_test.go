@@ -0,0 +1,252 @@
+package illustrator
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func lex(t *testing.T, src string) []Token {
+	t.Helper()
+
+	lx := NewLexer(strings.NewReader(src))
+	var tokens []Token
+	for {
+		token, err := lx.Next()
+		if err == io.EOF {
+			return tokens
+		}
+		if err != nil {
+			t.Fatalf("lex %q: %v", src, err)
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestLexer(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Token
+	}{
+		{
+			name: "numbers",
+			src:  "12 -3.5 .5 +1 1e3 16#FF",
+			want: []Token{
+				{TokenNumber, "12", 1},
+				{TokenNumber, "-3.5", 1},
+				{TokenNumber, ".5", 1},
+				{TokenNumber, "+1", 1},
+				{TokenNumber, "1e3", 1},
+				{TokenNumber, "16#FF", 1},
+			},
+		},
+		{
+			name: "operators",
+			src:  "m *u XA << >> { } -",
+			want: []Token{
+				{TokenOperator, "m", 1},
+				{TokenOperator, "*u", 1},
+				{TokenOperator, "XA", 1},
+				{TokenOperator, "<<", 1},
+				{TokenOperator, ">>", 1},
+				{TokenOperator, "{", 1},
+				{TokenOperator, "}", 1},
+				{TokenOperator, "-", 1},
+			},
+		},
+		{
+			name: "strings",
+			src:  `(Layer 1) (a (nested) string) (escaped \) \( \\) ()`,
+			want: []Token{
+				{TokenString, "(Layer 1)", 1},
+				{TokenString, "(a (nested) string)", 1},
+				{TokenString, `(escaped \) \( \\)`, 1},
+				{TokenString, "()", 1},
+			},
+		},
+		{
+			name: "hex strings",
+			src:  "<48656C6C6F> <48 65\n6C> <~87cURD]i~>",
+			want: []Token{
+				{TokenHexString, "<48656C6C6F>", 1},
+				{TokenHexString, "<48 65\n6C>", 1},
+				{TokenHexString, "<~87cURD]i~>", 2},
+			},
+		},
+		{
+			name: "names",
+			src:  "/Name /a.b-c //immediate /",
+			want: []Token{
+				{TokenName, "/Name", 1},
+				{TokenName, "/a.b-c", 1},
+				{TokenName, "//immediate", 1},
+				{TokenName, "/", 1},
+			},
+		},
+		{
+			name: "arrays",
+			src:  "[1 0 0 1 0 0] concat [/a[(b)]]",
+			want: []Token{
+				{TokenArrayStart, "[", 1},
+				{TokenNumber, "1", 1},
+				{TokenNumber, "0", 1},
+				{TokenNumber, "0", 1},
+				{TokenNumber, "1", 1},
+				{TokenNumber, "0", 1},
+				{TokenNumber, "0", 1},
+				{TokenArrayEnd, "]", 1},
+				{TokenOperator, "concat", 1},
+				{TokenArrayStart, "[", 1},
+				{TokenName, "/a", 1},
+				{TokenArrayStart, "[", 1},
+				{TokenString, "(b)", 1},
+				{TokenArrayEnd, "]", 1},
+				{TokenArrayEnd, "]", 1},
+			},
+		},
+		{
+			name: "comments",
+			src:  "%%BeginSetup\n1 w % trailing comment\r\n%AI5_BeginLayer",
+			want: []Token{
+				{TokenComment, "%%BeginSetup", 1},
+				{TokenNumber, "1", 2},
+				{TokenOperator, "w", 2},
+				{TokenComment, "% trailing comment", 2},
+				{TokenComment, "%AI5_BeginLayer", 3},
+			},
+		},
+		{
+			name: "continuation lines",
+			src:  "%_0 0 m\n%_/Name 1 Xa\n%_%comment",
+			want: []Token{
+				{TokenNumber, "0", 1},
+				{TokenNumber, "0", 1},
+				{TokenOperator, "m", 1},
+				{TokenName, "/Name", 2},
+				{TokenNumber, "1", 2},
+				{TokenOperator, "Xa", 2},
+				{TokenComment, "%comment", 3},
+			},
+		},
+		{
+			name: "operands over several lines",
+			src:  "1 0 0\n1 0 0\nconcat\n(two\nlines) Ln\r(cr)\r\n[1\n2] 0 d",
+			want: []Token{
+				{TokenNumber, "1", 1},
+				{TokenNumber, "0", 1},
+				{TokenNumber, "0", 1},
+				{TokenNumber, "1", 2},
+				{TokenNumber, "0", 2},
+				{TokenNumber, "0", 2},
+				{TokenOperator, "concat", 3},
+				{TokenString, "(two\nlines)", 4},
+				{TokenOperator, "Ln", 5},
+				{TokenString, "(cr)", 6},
+				{TokenArrayStart, "[", 7},
+				{TokenNumber, "1", 7},
+				{TokenNumber, "2", 8},
+				{TokenArrayEnd, "]", 8},
+				{TokenNumber, "0", 8},
+				{TokenOperator, "d", 8},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lex(t, tt.src)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d tokens %v, want %d %v", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("token %d: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"unterminated string", "(abc (def)"},
+		{"unterminated escape", `(abc\`},
+		{"unterminated hex string", "<4865"},
+		{"unterminated ascii85 string", "<~87cUR~"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lx := NewLexer(strings.NewReader(tt.src))
+			_, err := lx.Next()
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("got %v, want io.ErrUnexpectedEOF", err)
+			}
+		})
+	}
+}
+
+func TestTokenValue(t *testing.T) {
+	tests := []struct {
+		token Token
+		want  string
+	}{
+		{Token{Kind: TokenString, Text: `(Layer \(1\))`}, "Layer (1)"},
+		{Token{Kind: TokenString, Text: `(a\nb\101\\)`}, "a\nbA\\"},
+		{Token{Kind: TokenHexString, Text: "<48 65 6C 6C 6F>"}, "Hello"},
+		{Token{Kind: TokenHexString, Text: "<486>"}, "H`"},
+		{Token{Kind: TokenName, Text: "/Name"}, "Name"},
+		{Token{Kind: TokenNumber, Text: "12"}, "12"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.token.Value(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.token.Text, got, tt.want)
+		}
+	}
+}
+
+func TestTokenFloat(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"12", 12},
+		{"-3.5", -3.5},
+		{".5", 0.5},
+		{"16#FF", 255},
+		{"8#17", 15},
+	}
+
+	for _, tt := range tests {
+		token := Token{Kind: TokenNumber, Text: tt.text}
+		if got := token.Float(); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLexerReadLine(t *testing.T) {
+	lx := NewLexer(strings.NewReader("%AI5_BeginRaster\r\nbinary data\rnext\n"))
+	if _, err := lx.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"", "binary data", "next"} {
+		line, err := lx.ReadLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != want {
+			t.Errorf("got %q, want %q", line, want)
+		}
+	}
+	if lx.Line() != 4 {
+		t.Errorf("line %d, want 4", lx.Line())
+	}
+}
@@ -0,0 +1,76 @@
+package illustrator
+
+import "math"
+
+// Matrix is a postscript transformation matrix [a b c d tx ty], mapping
+// (x, y) to (a*x + c*y + tx, b*x + d*y + ty)
+type Matrix [6]float64
+
+func Identity() Matrix {
+	return Matrix{1, 0, 0, 1, 0, 0}
+}
+
+func Translate(tx, ty float64) Matrix {
+	return Matrix{1, 0, 0, 1, tx, ty}
+}
+
+func Scale(sx, sy float64) Matrix {
+	return Matrix{sx, 0, 0, sy, 0, 0}
+}
+
+func (m Matrix) IsIdentity() bool {
+	return m == Identity()
+}
+
+// Multiply returns the matrix applying m then n
+func (m Matrix) Multiply(n Matrix) Matrix {
+	return Matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// Invert returns the inverse matrix, false when m is not invertible
+func (m Matrix) Invert() (Matrix, bool) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if math.Abs(det) < 1e-12 {
+		return m, false
+	}
+
+	return Matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}, true
+}
+
+func (m Matrix) Apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// MatrixArgs parses [ a b c d tx ty ] or a b c d tx ty
+func MatrixArgs(vals []string) Matrix {
+	nums := make([]string, 0, 6)
+	for _, v := range vals {
+		if v != "[" && v != "]" {
+			nums = append(nums, v)
+		}
+	}
+
+	if len(nums) < 6 {
+		return Identity()
+	}
+
+	var m Matrix
+	for i, v := range nums[len(nums)-6:] {
+		m[i] = toFloat(v)
+	}
+	return m
+}
@@ -0,0 +1,156 @@
+package illustrator
+
+import (
+	"math"
+)
+
+var (
+	AI8_BeginMesh = []byte("%AI8_BeginMesh")
+	AI8_EndMesh   = []byte("%AI8_EndMesh")
+)
+
+// maxMeshNodes bounds the nodes allocated for the size of a mesh
+const maxMeshNodes = 1 << 16
+
+// MeshNode is a vertex of a gradient mesh.
+type MeshNode struct {
+	Row int
+	Col int
+
+	Point [2]float64
+	// control points of the four curves leaving the node:
+	// 0-up(row-1); 1-right(col+1); 2-down(row+1); 3-left(col-1)
+	Handles [4][2]float64
+	Color   [3]uint8
+}
+
+// Mesh is a gradient mesh made of Rows x Cols coons patches.
+type Mesh struct {
+	Version int
+	Rows    int // number of patch rows
+	Cols    int // number of patch columns
+
+	Nodes []MeshNode // (Rows+1)*(Cols+1) nodes, row-major
+}
+
+func (m *Mesh) init(rows, cols int) {
+	m.Rows, m.Cols = rows, cols
+	m.Nodes = make([]MeshNode, (rows+1)*(cols+1))
+	for i := range m.Nodes {
+		m.Nodes[i].Row = i / (cols + 1)
+		m.Nodes[i].Col = i % (cols + 1)
+	}
+}
+
+func (m *Mesh) Node(row, col int) *MeshNode {
+	if row < 0 || row > m.Rows || col < 0 || col > m.Cols {
+		return nil
+	}
+
+	return &m.Nodes[row*(m.Cols+1)+col]
+}
+
+// Patch returns the coons patch between nodes (row, col) and (row+1, col+1).
+func (m *Mesh) Patch(row, col int) *MeshPatch {
+	n00, n01 := m.Node(row, col), m.Node(row, col+1)
+	n10, n11 := m.Node(row+1, col), m.Node(row+1, col+1)
+	if n00 == nil || n11 == nil {
+		return nil
+	}
+
+	return &MeshPatch{
+		Top:    [4][2]float64{n00.Point, n00.Handles[1], n01.Handles[3], n01.Point},
+		Bottom: [4][2]float64{n10.Point, n10.Handles[1], n11.Handles[3], n11.Point},
+		Left:   [4][2]float64{n00.Point, n00.Handles[2], n10.Handles[0], n10.Point},
+		Right:  [4][2]float64{n01.Point, n01.Handles[2], n11.Handles[0], n11.Point},
+		Colors: [4][3]uint8{n00.Color, n01.Color, n10.Color, n11.Color},
+	}
+}
+
+// MeshPatch is a coons patch bounded by four cubic bezier curves.
+type MeshPatch struct {
+	Top    [4][2]float64 // (0,0) -> (1,0)
+	Bottom [4][2]float64 // (0,1) -> (1,1)
+	Left   [4][2]float64 // (0,0) -> (0,1)
+	Right  [4][2]float64 // (1,0) -> (1,1)
+
+	Colors [4][3]uint8 // (0,0), (1,0), (0,1), (1,1)
+}
+
+func bezier(p [4][2]float64, t float64) (x, y float64) {
+	mt := 1 - t
+	a, b, c, d := mt*mt*mt, 3*mt*mt*t, 3*mt*t*t, t*t*t
+	x = a*p[0][0] + b*p[1][0] + c*p[2][0] + d*p[3][0]
+	y = a*p[0][1] + b*p[1][1] + c*p[2][1] + d*p[3][1]
+	return
+}
+
+// Point evaluates the patch surface at (u, v), both between 0 and 1.
+func (p *MeshPatch) Point(u, v float64) (x, y float64) {
+	tx, ty := bezier(p.Top, u)
+	bx, by := bezier(p.Bottom, u)
+	lx, ly := bezier(p.Left, v)
+	rx, ry := bezier(p.Right, v)
+
+	p00, p10 := p.Top[0], p.Top[3]
+	p01, p11 := p.Bottom[0], p.Bottom[3]
+
+	x = (1-v)*tx + v*bx + (1-u)*lx + u*rx -
+		((1-u)*(1-v)*p00[0] + u*(1-v)*p10[0] + (1-u)*v*p01[0] + u*v*p11[0])
+	y = (1-v)*ty + v*by + (1-u)*ly + u*ry -
+		((1-u)*(1-v)*p00[1] + u*(1-v)*p10[1] + (1-u)*v*p01[1] + u*v*p11[1])
+	return
+}
+
+// Color returns the bilinear interpolated color at (u, v).
+func (p *MeshPatch) Color(u, v float64) [3]uint8 {
+	var rgb [3]uint8
+	for i := range rgb {
+		c := (1-u)*(1-v)*float64(p.Colors[0][i]) + u*(1-v)*float64(p.Colors[1][i]) +
+			(1-u)*v*float64(p.Colors[2][i]) + u*v*float64(p.Colors[3][i])
+		rgb[i] = uint8(math.Round(c))
+	}
+	return rgb
+}
+
+// Subdivisions returns the number of steps in each direction needed to
+// approximate the patch with polygons no larger than tolerance.
+func (p *MeshPatch) Subdivisions(tolerance float64) int {
+	if tolerance <= 0 {
+		tolerance = 1
+	}
+
+	var length float64
+	for _, c := range [][4][2]float64{p.Top, p.Bottom, p.Left, p.Right} {
+		var l float64
+		for i := 1; i < 4; i++ {
+			l += math.Hypot(c[i][0]-c[i-1][0], c[i][1]-c[i-1][1])
+		}
+		length = math.Max(length, l)
+	}
+
+	n := int(math.Ceil(length / tolerance))
+	if n < 1 {
+		n = 1
+	} else if n > 64 {
+		n = 64
+	}
+	return n
+}
+
+// XcArgs parses the color of a mesh node: gray, r g b or c m y k values.
+func XcArgs(vals []string) [3]uint8 {
+	switch len(vals) {
+	case 1:
+		gray := uint8(math.Round(toFloat(vals[0]) * 255))
+		return [3]uint8{gray, gray, gray}
+	case 3:
+		args := XAArgs(vals)
+		return args.RGB()
+	case 4:
+		args := KArgs(vals)
+		return args.CMYK2RGB()
+	}
+
+	return [3]uint8{}
+}
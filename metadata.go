@@ -0,0 +1,379 @@
+package illustrator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+var ErrNoMetadata = errors.New("illustrator: no xmp metadata")
+
+// xmp namespaces
+const (
+	nsRDF     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC      = "http://purl.org/dc/elements/1.1/"
+	nsXMP     = "http://ns.adobe.com/xap/1.0/"
+	nsXMPMM   = "http://ns.adobe.com/xap/1.0/mm/"
+	nsXMPTPg  = "http://ns.adobe.com/xap/1.0/t/pg/"
+	nsXMPG    = "http://ns.adobe.com/xap/1.0/g/"
+	nsXMPGImg = "http://ns.adobe.com/xap/1.0/g/img/"
+	nsStDim   = "http://ns.adobe.com/xap/1.0/sType/Dimensions#"
+	nsStFnt   = "http://ns.adobe.com/xap/1.0/sType/Font#"
+)
+
+// Metadata is the xmp metadata of the document
+type Metadata struct {
+	Title        string
+	Format       string
+	CreatorTool  string
+	CreateDate   time.Time
+	ModifyDate   time.Time
+	MetadataDate time.Time
+	DocumentID   string
+	InstanceID   string
+	MaxPageSize  PageSize
+	NPages       int
+	Fonts        []Font
+	PlateNames   []string
+	SwatchGroups []SwatchGroup
+	Thumbnails   []Thumbnail
+
+	Raw []byte // the xmp packet
+}
+
+// PageSize is xmpTPg:MaxPageSize
+type PageSize struct {
+	W, H float64
+	Unit string // Points, Inches, Millimeters ...
+}
+
+// Font is a font used by the document
+type Font struct {
+	Name      string
+	Family    string
+	Face      string
+	Type      string // TrueType, Type 1, Open Type ...
+	Version   string
+	Composite bool
+	File      string
+}
+
+type SwatchGroup struct {
+	Name      string
+	Type      int // 0 for the default group, 1 for color groups
+	Colorants []Swatch
+}
+
+// Swatch is a colorant of a swatch group, Color is set for CMYK and RGB
+// swatches
+type Swatch struct {
+	Name  string
+	Mode  string // CMYK, RGB, LAB, GRAY
+	Type  string // PROCESS, SPOT
+	Tint  float64
+	Color Color
+}
+
+// Thumbnail is the preview image of the document
+type Thumbnail struct {
+	Width  int
+	Height int
+	Format string // JPEG
+	Image  string // base64
+}
+
+// Data decodes the base64 image
+func (t Thumbnail) Data() ([]byte, error) {
+	clean := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, t.Image)
+	return base64.StdEncoding.DecodeString(clean)
+}
+
+// XMP returns the xmp packet of the document catalog, or of the
+// illustrator AIMetaData stream when the catalog has none
+func (r *Reader) XMP() ([]byte, error) {
+	if obj, ok := r.GetCatalogMetadata(); ok {
+		if stream, ok := core.GetStream(obj); ok {
+			data, err := streamData(stream)
+			if err != nil {
+				return nil, err
+			}
+			return data, nil
+		}
+	}
+
+	if stream := r.GetAiMetaData(); stream != nil {
+		data, err := streamData(stream)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Contains(data, []byte("<x:xmpmeta")) {
+			return data, nil
+		}
+	}
+	return nil, ErrNoMetadata
+}
+
+// Metadata returns the parsed xmp metadata
+func (r *Reader) Metadata() (*Metadata, error) {
+	data, err := r.XMP()
+	if err != nil {
+		return nil, err
+	}
+	return ParseXMP(data)
+}
+
+// xmlNode is an element of the xmp packet
+type xmlNode struct {
+	Name     xml.Name
+	Attr     []xml.Attr
+	Children []*xmlNode
+	Text     string
+}
+
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if len(stack) == 1 && len(root.Children) > 0 {
+				break
+			}
+			return nil, err
+		}
+
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name, Attr: t.Attr}
+			top.Children = append(top.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			top.Text += string(t)
+		}
+	}
+	return root, nil
+}
+
+func (n *xmlNode) find(space, local string) []*xmlNode {
+	var nodes []*xmlNode
+	for _, c := range n.Children {
+		if c.Name.Space == space && c.Name.Local == local {
+			nodes = append(nodes, c)
+		}
+		nodes = append(nodes, c.find(space, local)...)
+	}
+	return nodes
+}
+
+func (n *xmlNode) child(space, local string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name.Space == space && c.Name.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// prop returns a simple property, written as an attribute or an element
+func (n *xmlNode) prop(space, local string) string {
+	for _, a := range n.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+
+	if c := n.child(space, local); c != nil {
+		// rdf:Alt, rdf:Seq or rdf:Bag, the first item
+		if items := c.items(); len(items) > 0 {
+			return strings.TrimSpace(items[0].Text)
+		}
+		return strings.TrimSpace(c.Text)
+	}
+	return ""
+}
+
+// items returns the rdf:li items of a property
+func (n *xmlNode) items() []*xmlNode {
+	for _, c := range n.Children {
+		if c.Name.Space == nsRDF {
+			switch c.Name.Local {
+			case "Alt", "Seq", "Bag":
+				var items []*xmlNode
+				for _, li := range c.Children {
+					if li.Name.Space == nsRDF && li.Name.Local == "li" {
+						items = append(items, li)
+					}
+				}
+				return items
+			}
+		}
+	}
+	return nil
+}
+
+// structure returns the node holding the fields of a structured property,
+// rdf:parseType="Resource" or a nested rdf:Description
+func (n *xmlNode) structure() *xmlNode {
+	if d := n.child(nsRDF, "Description"); d != nil {
+		return d
+	}
+	return n
+}
+
+// ParseXMP parses an xmp packet
+func ParseXMP(data []byte) (*Metadata, error) {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Metadata{Raw: data}
+
+	// the properties are spread over several rdf:Description
+	descs := root.find(nsRDF, "Description")
+	prop := func(space, local string) string {
+		for _, d := range descs {
+			if v := d.prop(space, local); len(v) > 0 {
+				return v
+			}
+		}
+		return ""
+	}
+	node := func(space, local string) *xmlNode {
+		for _, d := range descs {
+			if c := d.child(space, local); c != nil {
+				return c
+			}
+		}
+		return nil
+	}
+
+	m.Title = prop(nsDC, "title")
+	m.Format = prop(nsDC, "format")
+	m.CreatorTool = prop(nsXMP, "CreatorTool")
+	m.CreateDate = parseXMPDate(prop(nsXMP, "CreateDate"))
+	m.ModifyDate = parseXMPDate(prop(nsXMP, "ModifyDate"))
+	m.MetadataDate = parseXMPDate(prop(nsXMP, "MetadataDate"))
+	m.DocumentID = prop(nsXMPMM, "DocumentID")
+	m.InstanceID = prop(nsXMPMM, "InstanceID")
+	m.NPages, _ = strconv.Atoi(prop(nsXMPTPg, "NPages"))
+
+	if n := node(nsXMPTPg, "MaxPageSize"); n != nil {
+		s := n.structure()
+		m.MaxPageSize.W, _ = strconv.ParseFloat(s.prop(nsStDim, "w"), 64)
+		m.MaxPageSize.H, _ = strconv.ParseFloat(s.prop(nsStDim, "h"), 64)
+		m.MaxPageSize.Unit = s.prop(nsStDim, "unit")
+	}
+
+	if n := node(nsXMPTPg, "Fonts"); n != nil {
+		for _, li := range n.items() {
+			s := li.structure()
+			m.Fonts = append(m.Fonts, Font{
+				Name:      s.prop(nsStFnt, "fontName"),
+				Family:    s.prop(nsStFnt, "fontFamily"),
+				Face:      s.prop(nsStFnt, "fontFace"),
+				Type:      s.prop(nsStFnt, "fontType"),
+				Version:   s.prop(nsStFnt, "versionString"),
+				Composite: s.prop(nsStFnt, "composite") == "True",
+				File:      s.prop(nsStFnt, "fontFileName"),
+			})
+		}
+	}
+
+	if n := node(nsXMPTPg, "PlateNames"); n != nil {
+		for _, li := range n.items() {
+			m.PlateNames = append(m.PlateNames, strings.TrimSpace(li.Text))
+		}
+	}
+
+	if n := node(nsXMPTPg, "SwatchGroups"); n != nil {
+		for _, li := range n.items() {
+			s := li.structure()
+			group := SwatchGroup{Name: s.prop(nsXMPG, "groupName")}
+			group.Type, _ = strconv.Atoi(s.prop(nsXMPG, "groupType"))
+			if c := s.child(nsXMPG, "Colorants"); c != nil {
+				for _, item := range c.items() {
+					group.Colorants = append(group.Colorants, parseSwatch(item.structure()))
+				}
+			}
+			m.SwatchGroups = append(m.SwatchGroups, group)
+		}
+	}
+
+	if n := node(nsXMP, "Thumbnails"); n != nil {
+		for _, li := range n.items() {
+			s := li.structure()
+			t := Thumbnail{
+				Format: s.prop(nsXMPGImg, "format"),
+				Image:  s.prop(nsXMPGImg, "image"),
+			}
+			t.Width, _ = strconv.Atoi(s.prop(nsXMPGImg, "width"))
+			t.Height, _ = strconv.Atoi(s.prop(nsXMPGImg, "height"))
+			m.Thumbnails = append(m.Thumbnails, t)
+		}
+	}
+
+	return m, nil
+}
+
+func parseSwatch(n *xmlNode) Swatch {
+	f := func(local string) float64 {
+		v, _ := strconv.ParseFloat(n.prop(nsXMPG, local), 64)
+		return v
+	}
+
+	s := Swatch{
+		Name: n.prop(nsXMPG, "swatchName"),
+		Mode: n.prop(nsXMPG, "mode"),
+		Type: n.prop(nsXMPG, "type"),
+		Tint: f("tint"),
+	}
+
+	// cmyk in percent, rgb in 0-255
+	switch s.Mode {
+	case "CMYK":
+		s.Color = CMYKColor(f("cyan")/100, f("magenta")/100, f("yellow")/100, f("black")/100)
+	case "RGB":
+		s.Color = RGBColor(f("red")/255, f("green")/255, f("blue")/255)
+	}
+	return s
+}
+
+// parseXMPDate parses the date formats allowed by xmp, zero when invalid
+func parseXMPDate(s string) time.Time {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04",
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
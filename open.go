@@ -0,0 +1,160 @@
+package illustrator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+var (
+	ErrNotIllustrator         = errors.New("illustrator: not an illustrator file")
+	ErrNoPrivateData          = errors.New("illustrator: no illustrator private data, the file is saved as pdf compatible only")
+	ErrUnsupportedCompression = errors.New("illustrator: unsupported compression of private data")
+)
+
+type Format int8
+
+const (
+	FormatUnknown    Format = iota
+	FormatPostScript        // illustrator 8 and earlier, or eps
+	FormatPDF               // illustrator 9 and later
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatPostScript:
+		return "postscript"
+	case FormatPDF:
+		return "pdf"
+	}
+	return "unknown"
+}
+
+// dos eps binary header: magic, postscript offset and length
+var epsMagic = []byte{0xC5, 0xD0, 0xD3, 0xC6}
+
+// File is an opened illustrator file
+type File struct {
+	Format Format
+	PDF    *Reader // pdf container, nil for postscript files
+
+	path    string
+	file    *os.File  // nil for OpenReaderAt
+	ps      io.Reader // postscript section
+	closers []io.Closer
+}
+
+// Open sniffs the format of the file at path, Close must be called once
+// the file is no longer used
+func Open(path string, options ...func(*ReaderOption)) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	f, err := newFile(file, stat.Size(), options)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	f.path = path
+	f.file = file
+	return f, nil
+}
+
+// OpenReaderAt sniffs the format of size bytes of r, for uploads and
+// range-readable blobs, e.g. bytes.NewReader(data)
+func OpenReaderAt(r io.ReaderAt, size int64, options ...func(*ReaderOption)) (*File, error) {
+	return newFile(r, size, options)
+}
+
+func newFile(r io.ReaderAt, size int64, options []func(*ReaderOption)) (*File, error) {
+	head := make([]byte, 1024)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, ErrNotIllustrator
+	}
+
+	f := &File{}
+	switch {
+	case bytes.HasPrefix(head, epsMagic):
+		if len(head) < 12 {
+			return nil, ErrNotIllustrator
+		}
+		offset := binary.LittleEndian.Uint32(head[4:8])
+		length := binary.LittleEndian.Uint32(head[8:12])
+		f.Format = FormatPostScript
+		f.ps = io.NewSectionReader(r, int64(offset), int64(length))
+	case bytes.HasPrefix(head, []byte("%!PS-Adobe")):
+		f.Format = FormatPostScript
+		f.ps = io.NewSectionReader(r, 0, size)
+	case bytes.Contains(head, []byte("%PDF-")):
+		reader, err := NewReaderAt(r, size, options...)
+		if err != nil {
+			return nil, err
+		}
+		f.Format = FormatPDF
+		f.PDF = reader
+	default:
+		return nil, ErrNotIllustrator
+	}
+
+	return f, nil
+}
+
+// PrivateData returns the illustrator artwork as postscript, either the
+// file itself or the private data of a pdf file
+func (f *File) PrivateData() (io.Reader, error) {
+	if f.Format == FormatPostScript {
+		return f.ps, nil
+	}
+
+	rd, err := f.PDF.AIPrivateDataReader()
+	if err != nil {
+		return nil, err
+	}
+	f.closers = append(f.closers, rd)
+	return rd, nil
+}
+
+// AIReader returns a reader of the artwork, links of placed art are
+// resolved relative to the file
+func (f *File) AIReader() (*AIReader, error) {
+	data, err := f.PrivateData()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := NewAIReader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.path) > 0 {
+		reader.SetLinkResolver(RelativeLinkResolver(f.path))
+	}
+	return reader, nil
+}
+
+func (f *File) Close() error {
+	for _, c := range f.closers {
+		c.Close()
+	}
+	f.closers = nil
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
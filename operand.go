@@ -0,0 +1,47 @@
+package illustrator
+
+// operandStack holds the operands of the next operator
+type operandStack struct {
+	stack []string
+}
+
+func (st *operandStack) Len() int {
+	return len(st.stack)
+}
+
+func (st *operandStack) Reset() {
+	st.stack = st.stack[:0]
+}
+
+func (st *operandStack) Push(v string) {
+	st.stack = append(st.stack, v)
+}
+
+func (st *operandStack) Pop() (v string) {
+	if l := len(st.stack); l > 0 {
+		v = st.stack[l-1]
+		st.stack = st.stack[:l-1]
+	}
+	return
+}
+
+func (st *operandStack) PopN(n int) (vals []string) {
+	if l := len(st.stack); l >= n {
+		vals = st.stack[l-n : l]
+		st.stack = st.stack[:l-n]
+	}
+	return
+}
+
+func (st *operandStack) PopAll() (vals []string) {
+	vals = st.stack
+	st.stack = st.stack[len(st.stack):]
+	return
+}
+
+func (st *operandStack) Top() (v string) {
+	if l := len(st.stack); l > 0 {
+		v = st.stack[l-1]
+	}
+	return
+}
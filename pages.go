@@ -0,0 +1,118 @@
+package illustrator
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// PageBox holds the boxes of a pdf page. The pages are not necessarily the
+// artboards of the document, which are kept in the private data
+type PageBox struct {
+	Page     int // page number, from 1
+	MediaBox [4]float64
+	CropBox  [4]float64 // defaults to MediaBox
+	BleedBox [4]float64 // defaults to CropBox
+	TrimBox  [4]float64 // defaults to CropBox
+	ArtBox   [4]float64 // defaults to CropBox
+	Rotate   int
+}
+
+// findIllustratorPage returns the page carrying the illustrator piece info,
+// the first page when no page carries it
+func findIllustratorPage(reader *model.PdfReader) (*model.PdfPage, int, error) {
+	n, err := reader.GetNumPages()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := 1; i <= n; i++ {
+		page, err := reader.GetPage(i)
+		if err != nil {
+			return nil, 0, err
+		}
+		if pageIllustrator(page) != nil {
+			return page, i, nil
+		}
+	}
+
+	page, err := reader.GetPage(1)
+	if err != nil {
+		return nil, 0, err
+	}
+	return page, 1, nil
+}
+
+// NumPages returns the number of pages
+func (r *Reader) NumPages() int {
+	n, _ := r.GetNumPages()
+	return n
+}
+
+// PageNumber returns the number of the page read by r, the page carrying
+// the illustrator piece info
+func (r *Reader) PageNumber() int {
+	return r.pageNum
+}
+
+// Page returns the page read by r
+func (r *Reader) Page() *model.PdfPage {
+	return r.page
+}
+
+// SelectPage returns a reader of page n, sharing the pdf with r. The
+// private data, DrawContent and Thumbnail are read from page n.
+func (r *Reader) SelectPage(n int) (*Reader, error) {
+	if n < 1 || n > r.NumPages() {
+		return nil, fmt.Errorf("illustrator: page %d out of range", n)
+	}
+
+	page, err := r.GetPage(n)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *r
+	c.page = page
+	c.pageNum = n
+	return &c, nil
+}
+
+// PageBoxes returns the boxes of every page
+func (r *Reader) PageBoxes() ([]PageBox, error) {
+	n := r.NumPages()
+	boxes := make([]PageBox, 0, n)
+	for i := 1; i <= n; i++ {
+		page, err := r.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+
+		media, err := page.GetMediaBox()
+		if err != nil {
+			return nil, err
+		}
+
+		a := PageBox{Page: i, MediaBox: rectangle(media)}
+		a.CropBox = boxOr(page.CropBox, a.MediaBox)
+		a.BleedBox = boxOr(page.BleedBox, a.CropBox)
+		a.TrimBox = boxOr(page.TrimBox, a.CropBox)
+		a.ArtBox = boxOr(page.ArtBox, a.CropBox)
+		if rotate, err := page.GetRotate(); err == nil {
+			a.Rotate = int(rotate)
+		}
+		boxes = append(boxes, a)
+	}
+	return boxes, nil
+}
+
+func rectangle(r *model.PdfRectangle) [4]float64 {
+	return [4]float64{r.Llx, r.Lly, r.Urx, r.Ury}
+}
+
+func boxOr(r *model.PdfRectangle, def [4]float64) [4]float64 {
+	if r == nil {
+		return def
+	}
+	return rectangle(r)
+}
@@ -0,0 +1,115 @@
+package illustrator
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// multiPagePDF returns a two page file, the piece info is on page 2 when
+// private is set
+func multiPagePDF(t *testing.T, private bool) []byte {
+	pieceInfo := ""
+	if private {
+		pieceInfo = " /PieceInfo << /Illustrator << /Private 5 0 R >> >>"
+	}
+	return testPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 100 100] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 300 200] /TrimBox [10 10 290 190] /Rotate 90 /Contents 8 0 R" + pieceInfo + " >>",
+		"<< /NumBlock 1 /AIPrivateData1 6 0 R >>",
+		streamObj(encodePS(t, "zlib", []byte(samplePS))),
+		streamObj([]byte("1 0 0 rg 0 0 50 50 re f")),
+		streamObj([]byte("0 0 1 rg 0 0 80 80 re f")),
+	}, false)
+}
+
+func TestFindIllustratorPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		private bool
+		page    int
+	}{
+		{"piece info on page 2", true, 2},
+		{"no piece info", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewBytesReader(multiPagePDF(t, tt.private))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.NumPages() != 2 || r.PageNumber() != tt.page {
+				t.Errorf("page %d of %d, want %d of 2", r.PageNumber(), r.NumPages(), tt.page)
+			}
+
+			_, err = r.GetAIPrivateData()
+			if tt.private && err != nil {
+				t.Error(err)
+			}
+			if !tt.private && !errors.Is(err, ErrNoPrivateData) {
+				t.Errorf("got %v, want ErrNoPrivateData", err)
+			}
+		})
+	}
+}
+
+func TestSelectPage(t *testing.T) {
+	r, err := NewBytesReader(multiPagePDF(t, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := r.SelectPage(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.PageNumber() != 1 || r.PageNumber() != 2 {
+		t.Errorf("selected page %d, reader page %d", first.PageNumber(), r.PageNumber())
+	}
+	if _, err := first.GetAIPrivateData(); !errors.Is(err, ErrNoPrivateData) {
+		t.Errorf("page 1: got %v, want ErrNoPrivateData", err)
+	}
+
+	rec := NewRecordingDrawer(nil)
+	if err := first.DrawContent(rec); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	rec.WriteText(&buf)
+	if !strings.Contains(buf.String(), "SetColor(fill, rgb[1 0 0])") || !strings.Contains(buf.String(), "Lineto(50, 50)") {
+		t.Errorf("page 1 content:\n%s", buf.String())
+	}
+
+	for _, n := range []int{0, 3} {
+		if _, err := r.SelectPage(n); err == nil {
+			t.Errorf("SelectPage(%d) got no error", n)
+		}
+	}
+}
+
+func TestPageBoxes(t *testing.T) {
+	r, err := NewBytesReader(multiPagePDF(t, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boxes, err := r.PageBoxes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page1 := [4]float64{0, 0, 100, 100}
+	page2 := [4]float64{0, 0, 300, 200}
+	want := []PageBox{
+		{Page: 1, MediaBox: page1, CropBox: page1, BleedBox: page1, TrimBox: page1, ArtBox: page1},
+		{Page: 2, MediaBox: page2, CropBox: page2, BleedBox: page2, TrimBox: [4]float64{10, 10, 290, 190}, ArtBox: page2, Rotate: 90},
+	}
+	if !reflect.DeepEqual(boxes, want) {
+		t.Errorf("got %+v\nwant %+v", boxes, want)
+	}
+}
@@ -0,0 +1,124 @@
+package illustrator
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const samplePS = "%!PS-Adobe-3.0\n%%Creator: Adobe Illustrator(R) 24.0\n%%AI8_CreatorVersion: 24.0.0\n%%EndComments\n" +
+	"%AI5_BeginLayer\n1 1 1 1 0 0 -1 79 128 255 Lb\n(Layer 1) Ln\n0 0 m\n100 100 L\nS\nLB\n%%EOF\n"
+
+// testPDF returns a pdf of objs, object i+1 is objs[i], with a
+// cross-reference table or a cross-reference stream
+func testPDF(objs []string, xrefStream bool) []byte {
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.5\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objs))
+	for i, obj := range objs {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	start := out.Len()
+	if !xrefStream {
+		fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+		for _, offset := range offsets {
+			fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+		}
+		fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, start)
+		return out.Bytes()
+	}
+
+	// the cross-reference stream is the last object
+	var x bytes.Buffer
+	x.Write([]byte{0, 0, 0, 0, 0, 0xff, 0xff})
+	for _, offset := range append(offsets, start) {
+		x.WriteByte(1)
+		binary.Write(&x, binary.BigEndian, uint32(offset))
+		x.Write([]byte{0, 0})
+	}
+	num := len(objs) + 1
+	fmt.Fprintf(&out, "%d 0 obj\n<< /Type /XRef /Size %d /W [1 4 2] /Root 1 0 R /Length %d >>\nstream\n", num, num+1, x.Len())
+	out.Write(x.Bytes())
+	fmt.Fprintf(&out, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", start)
+	return out.Bytes()
+}
+
+func streamObj(data []byte) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data)
+}
+
+// encodePS returns ps compressed with the header of the encoding, the
+// fixtures do not depend on the handles under test
+func encodePS(t *testing.T, name string, ps []byte) []byte {
+	t.Helper()
+
+	switch name {
+	case "zlib":
+		var b bytes.Buffer
+		w := zlib.NewWriter(&b)
+		w.Write(ps)
+		w.Close()
+		return append([]byte("%AI12_CompressedData"), b.Bytes()...)
+	case "zstd":
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append([]byte("%AI24_ZStandard_Data"), enc.EncodeAll(ps, nil)...)
+	case "none":
+		return ps
+	}
+	t.Fatalf("unknown encoding %s", name)
+	return nil
+}
+
+// splitData splits data into n chunks
+func splitData(data []byte, n int) [][]byte {
+	size := (len(data) + n - 1) / n
+	var chunks [][]byte
+	for i := 0; i < len(data); i += size {
+		end := i + size
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, data[i:end])
+	}
+	return chunks
+}
+
+// aiPDF returns a one page illustrator file with the chunks as the
+// AIPrivateDataN streams, a nil chunk is missing
+func aiPDF(chunks [][]byte, xrefStream bool) []byte {
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] /PieceInfo << /Illustrator 4 0 R >> >>",
+		"<< /Private 5 0 R /LastModified (D:20200101) >>",
+	}
+
+	var private strings.Builder
+	fmt.Fprintf(&private, "<< /ContainerVersion 11 /CreatorVersion 24 /RoundTripVersion 24 /NumBlock %d", len(chunks))
+	for i, chunk := range chunks {
+		if chunk != nil {
+			fmt.Fprintf(&private, " /AIPrivateData%d %d 0 R", i+1, 6+i)
+		}
+	}
+	private.WriteString(" >>")
+	objs = append(objs, private.String())
+
+	for _, chunk := range chunks {
+		if chunk == nil {
+			objs = append(objs, "null")
+		} else {
+			objs = append(objs, streamObj(chunk))
+		}
+	}
+	return testPDF(objs, xrefStream)
+}
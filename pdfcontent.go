@@ -0,0 +1,710 @@
+package illustrator
+
+import (
+	"errors"
+	"log"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// max nesting of form xobjects
+const maxFormDepth = 16
+
+// DrawContent draws the pdf content of the page, for files saved without
+// illustrator private data. Paths, colors, clips, images and forms are
+// drawn, axial and radial shadings are drawn as gradients, text and the
+// other shadings are skipped.
+func (r *Reader) DrawContent(d DrawerV2) error {
+	box, err := r.page.GetMediaBox()
+	if err != nil {
+		return err
+	}
+
+	content, err := r.page.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+
+	header := AIHeader{
+		BoundingBox:      [4]int{int(box.Llx), int(box.Lly), int(box.Urx), int(box.Ury)},
+		HiResBoundingBox: [4]float64{box.Llx, box.Lly, box.Urx, box.Ury},
+	}
+	d.SetHeader(&header)
+
+	d.BeginLayer(&AILayer{Name: "Layer 1", Visible: true, Preview: true, Enabled: true, Printing: true})
+	p := &contentInterpreter{d: d, gs: NewGraphicsState(), box: box}
+	err = p.run(content, r.page.Resources, 0)
+	for len(p.gstack) > 0 {
+		p.grestore()
+	}
+	p.closeGroups(0)
+	d.EndLayer()
+	return err
+}
+
+// contentInterpreter translates pdf content operators into Drawer calls
+type contentInterpreter struct {
+	d      DrawerV2
+	gs     GraphicsState
+	gstack []GraphicsState
+	box    *model.PdfRectangle
+
+	fillCS      model.PdfColorspace // nil for device color spaces
+	strokeCS    model.PdfColorspace
+	clip        bool // W or W*, the current path is a clipping path
+	clipEvenOdd bool // W*
+
+	path     []func(DrawerV2)   // segments of the current path, drawn when it is painted
+	clipPath []func(DrawerV2)   // segments of the last clipping path, nil for the page
+	clips    [][]func(DrawerV2) // clipPath saved by q
+	open     []bool             // groups opened by clips and transforms, true for a clip
+	opened   []int              // len(open) saved by q
+}
+
+func (p *contentInterpreter) run(content string, res *model.PdfPageResources, depth int) error {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		return err
+	}
+
+	for _, op := range *ops {
+		p.do(op, res, depth)
+	}
+	return nil
+}
+
+func (p *contentInterpreter) gsave() {
+	p.gstack = append(p.gstack, p.gs.Clone())
+	p.clips = append(p.clips, p.clipPath)
+	p.opened = append(p.opened, len(p.open))
+	p.d.Group()
+}
+
+func (p *contentInterpreter) grestore() {
+	l := len(p.gstack)
+	if l == 0 {
+		return
+	}
+
+	p.closeGroups(p.opened[l-1])
+	p.opened = p.opened[:l-1]
+	prev := p.gs
+	p.gs = p.gstack[l-1]
+	p.gstack = p.gstack[:l-1]
+	p.clipPath = p.clips[l-1]
+	p.clips = p.clips[:l-1]
+	p.d.EndGroup()
+	p.restoreStyle(&prev)
+}
+
+// closeGroups closes the clip groups and transforms opened after the
+// first n, innermost first
+func (p *contentInterpreter) closeGroups(n int) {
+	for i := len(p.open) - 1; i >= n; i-- {
+		if p.open[i] {
+			p.d.EndGroup()
+		} else {
+			p.d.PopTransform()
+		}
+	}
+	p.open = p.open[:n]
+}
+
+// restoreStyle sends the restored style to the drawer, which keeps its own
+// copy of the opacity and line style
+func (p *contentInterpreter) restoreStyle(prev *GraphicsState) {
+	gs := &p.gs
+	if gs.Fill != prev.Fill {
+		p.d.SetColor(AI_Fill, gs.Fill)
+	}
+	if gs.Stroke != prev.Stroke {
+		p.d.SetColor(AI_Stroke, gs.Stroke)
+	}
+	if gs.Opacity != prev.Opacity {
+		p.d.SetOpacity(gs.Opacity)
+	}
+	if gs.LineWidth != prev.LineWidth {
+		p.d.SetLineWidth(gs.LineWidth)
+	}
+	if gs.LineCap != prev.LineCap {
+		p.d.SetLineCap(gs.LineCap)
+	}
+	if gs.LineJoin != prev.LineJoin {
+		p.d.SetLineJoin(gs.LineJoin)
+	}
+	if gs.MiterLimit != prev.MiterLimit {
+		p.d.SetMiterLimit(gs.MiterLimit)
+	}
+	if len(gs.Dash) != len(prev.Dash) || gs.DashPhase != prev.DashPhase {
+		p.d.SetDash(gs.Dash, gs.DashPhase)
+	} else {
+		for i := range gs.Dash {
+			if gs.Dash[i] != prev.Dash[i] {
+				p.d.SetDash(gs.Dash, gs.DashPhase)
+				break
+			}
+		}
+	}
+}
+
+func (p *contentInterpreter) concat(m Matrix) {
+	p.gs.CTM = m.Multiply(p.gs.CTM)
+	p.open = append(p.open, false)
+	p.d.PushTransform(m)
+}
+
+func (p *contentInterpreter) setColor(op PathOp, c Color) {
+	p.gs.setColor(op, c)
+	p.d.SetColor(op, c)
+}
+
+// segment adds a segment to the current path
+func (p *contentInterpreter) segment(fn func(DrawerV2)) {
+	p.path = append(p.path, fn)
+}
+
+// paint draws the current path. A clipping path opens a group, like q, so
+// that the clip applies to the content after it until Q. The fill rule of
+// a clipping path that is not painted is the rule of W or W*
+func (p *contentInterpreter) paint(op PathOp, evenOdd bool) {
+	if p.clip {
+		p.d.Group()
+		p.open = append(p.open, true)
+	}
+	for _, fn := range p.path {
+		fn(p.d)
+	}
+	if p.clip {
+		p.d.ClipPath()
+		p.d.ApplyClip()
+		p.clipPath = p.path
+		if op == 0 {
+			evenOdd = p.clipEvenOdd
+		}
+	}
+	p.clip = false
+	p.path = nil
+
+	p.gs.EvenOdd = evenOdd
+	p.d.PathRender(op, &p.gs)
+	p.gs.EvenOdd = false
+}
+
+func (p *contentInterpreter) do(op *contentstream.ContentStreamOperation, res *model.PdfPageResources, depth int) {
+	// numeric operands, name operands are read from op.Params
+	vals, _ := core.GetNumbersAsFloat(op.Params)
+	need := func(n int) bool {
+		return len(vals) >= n
+	}
+
+	switch op.Operand {
+	case "q":
+		p.gsave()
+	case "Q":
+		p.grestore()
+	case "cm":
+		if need(6) {
+			p.concat(Matrix{vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]})
+		}
+	case "w":
+		if need(1) {
+			p.gs.LineWidth = vals[0]
+			p.d.SetLineWidth(vals[0])
+		}
+	case "J":
+		if need(1) {
+			p.gs.LineCap = LineCap(vals[0])
+			p.d.SetLineCap(p.gs.LineCap)
+		}
+	case "j":
+		if need(1) {
+			p.gs.LineJoin = LineJoin(vals[0])
+			p.d.SetLineJoin(p.gs.LineJoin)
+		}
+	case "M":
+		if need(1) {
+			p.gs.MiterLimit = vals[0]
+			p.d.SetMiterLimit(vals[0])
+		}
+	case "d":
+		if len(op.Params) == 2 {
+			arr, _ := core.GetArray(op.Params[0])
+			phase, _ := core.GetNumberAsFloat(op.Params[1])
+			var dash []float64
+			if arr != nil {
+				dash, _ = core.GetNumbersAsFloat(arr.Elements())
+			}
+			p.gs.Dash, p.gs.DashPhase = dash, phase
+			p.d.SetDash(dash, phase)
+		}
+	case "i":
+		if need(1) {
+			p.d.SetFlat(vals[0])
+		}
+	case "gs":
+		p.extGState(op.Params, res)
+
+	// path
+	case "m":
+		if need(2) {
+			p.segment(func(d DrawerV2) { d.Moveto(vals[0], vals[1]) })
+		}
+	case "l":
+		if need(2) {
+			p.segment(func(d DrawerV2) { d.Lineto(vals[0], vals[1]) })
+		}
+	case "c":
+		if need(6) {
+			p.segment(func(d DrawerV2) { d.Curveto(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]) })
+		}
+	case "v":
+		if need(4) {
+			p.segment(func(d DrawerV2) { d.Curveto2(vals[0], vals[1], vals[2], vals[3]) })
+		}
+	case "y":
+		if need(4) {
+			p.segment(func(d DrawerV2) { d.Curveto1(vals[0], vals[1], vals[2], vals[3]) })
+		}
+	case "h":
+		p.segment(func(d DrawerV2) { d.ClosePath() })
+	case "re":
+		if need(4) {
+			x, y, w, h := vals[0], vals[1], vals[2], vals[3]
+			p.segment(func(d DrawerV2) {
+				d.Moveto(x, y)
+				d.Lineto(x+w, y)
+				d.Lineto(x+w, y+h)
+				d.Lineto(x, y+h)
+				d.ClosePath()
+			})
+		}
+
+	// paint
+	case "S":
+		p.paint(AI_Stroke, false)
+	case "s":
+		p.segment(func(d DrawerV2) { d.ClosePath() })
+		p.paint(AI_Stroke, false)
+	case "f", "F":
+		p.paint(AI_Fill, false)
+	case "f*":
+		p.paint(AI_Fill, true)
+	case "B":
+		p.paint(AI_Fill|AI_Stroke, false)
+	case "B*":
+		p.paint(AI_Fill|AI_Stroke, true)
+	case "b":
+		p.segment(func(d DrawerV2) { d.ClosePath() })
+		p.paint(AI_Fill|AI_Stroke, false)
+	case "b*":
+		p.segment(func(d DrawerV2) { d.ClosePath() })
+		p.paint(AI_Fill|AI_Stroke, true)
+	case "n":
+		p.paint(0, false)
+	case "W", "W*":
+		p.clip = true
+		p.clipEvenOdd = op.Operand == "W*"
+
+	// color
+	case "g":
+		if need(1) {
+			p.fillCS = nil
+			p.setColor(AI_Fill, GrayColor(vals[0]))
+		}
+	case "G":
+		if need(1) {
+			p.strokeCS = nil
+			p.setColor(AI_Stroke, GrayColor(vals[0]))
+		}
+	case "rg":
+		if need(3) {
+			p.fillCS = nil
+			p.setColor(AI_Fill, RGBColor(vals[0], vals[1], vals[2]))
+		}
+	case "RG":
+		if need(3) {
+			p.strokeCS = nil
+			p.setColor(AI_Stroke, RGBColor(vals[0], vals[1], vals[2]))
+		}
+	case "k":
+		if need(4) {
+			p.fillCS = nil
+			p.setColor(AI_Fill, CMYKColor(vals[0], vals[1], vals[2], vals[3]))
+		}
+	case "K":
+		if need(4) {
+			p.strokeCS = nil
+			p.setColor(AI_Stroke, CMYKColor(vals[0], vals[1], vals[2], vals[3]))
+		}
+	case "cs":
+		p.fillCS = colorspace(op.Params, res)
+	case "CS":
+		p.strokeCS = colorspace(op.Params, res)
+	case "sc", "scn":
+		if c, ok := pdfColor(p.fillCS, numbers(op.Params)); ok {
+			p.setColor(AI_Fill, c)
+		}
+	case "SC", "SCN":
+		if c, ok := pdfColor(p.strokeCS, numbers(op.Params)); ok {
+			p.setColor(AI_Stroke, c)
+		}
+
+	// xobjects and shadings
+	case "Do":
+		p.xobject(op.Params, res, depth)
+	case "sh":
+		p.shading(op.Params, res)
+	}
+}
+
+// numbers returns the leading numeric operands, pattern names are skipped
+func numbers(params []core.PdfObject) []float64 {
+	var vals []float64
+	for _, obj := range params {
+		v, err := core.GetNumberAsFloat(obj)
+		if err != nil {
+			break
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+func colorspace(params []core.PdfObject, res *model.PdfPageResources) model.PdfColorspace {
+	if len(params) == 0 {
+		return nil
+	}
+
+	name, ok := core.GetName(params[0])
+	if !ok {
+		return nil
+	}
+
+	switch *name {
+	case "DeviceGray", "DeviceRGB", "DeviceCMYK", "Pattern":
+		return nil
+	}
+
+	if res != nil {
+		if cs, ok := res.GetColorspaceByName(*name); ok {
+			return cs
+		}
+	}
+	return nil
+}
+
+// pdfColor converts the components in color space cs, device color spaces
+// are told apart by the number of components
+func pdfColor(cs model.PdfColorspace, vals []float64) (Color, bool) {
+	if cs != nil {
+		switch cs.(type) {
+		case *model.PdfColorspaceDeviceGray, *model.PdfColorspaceDeviceRGB, *model.PdfColorspaceDeviceCMYK:
+		default:
+			c, err := cs.ColorFromFloats(vals)
+			if err != nil {
+				return Color{}, false
+			}
+
+			rgb, err := cs.ColorToRGB(c)
+			if err != nil {
+				return Color{}, false
+			}
+
+			if v, ok := rgb.(*model.PdfColorDeviceRGB); ok {
+				return RGBColor(v.R(), v.G(), v.B()), true
+			}
+			return Color{}, false
+		}
+	}
+
+	switch len(vals) {
+	case 1:
+		return GrayColor(vals[0]), true
+	case 3:
+		return RGBColor(vals[0], vals[1], vals[2]), true
+	case 4:
+		return CMYKColor(vals[0], vals[1], vals[2], vals[3]), true
+	}
+	return Color{}, false
+}
+
+func (p *contentInterpreter) extGState(params []core.PdfObject, res *model.PdfPageResources) {
+	if len(params) == 0 || res == nil {
+		return
+	}
+
+	name, ok := core.GetName(params[0])
+	if !ok {
+		return
+	}
+
+	obj, ok := res.GetExtGState(*name)
+	if !ok {
+		return
+	}
+
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return
+	}
+
+	if v, err := core.GetNumberAsFloat(dict.Get("LW")); err == nil {
+		p.gs.LineWidth = v
+		p.d.SetLineWidth(v)
+	}
+
+	// one opacity for fill and stroke, fill opacity wins
+	for _, key := range []core.PdfObjectName{"CA", "ca"} {
+		if v, err := core.GetNumberAsFloat(dict.Get(key)); err == nil {
+			p.gs.Opacity = v
+		}
+	}
+	p.d.SetOpacity(p.gs.Opacity)
+}
+
+func (p *contentInterpreter) xobject(params []core.PdfObject, res *model.PdfPageResources, depth int) {
+	if len(params) == 0 || res == nil {
+		return
+	}
+
+	name, ok := core.GetName(params[0])
+	if !ok {
+		return
+	}
+
+	_, kind := res.GetXObjectByName(*name)
+	switch kind {
+	case model.XObjectTypeImage:
+		raster, err := p.image(res, *name)
+		if err != nil {
+			log.Println("invalid image xobject:", *name, err)
+			return
+		}
+		p.d.SetRaster(raster)
+	case model.XObjectTypeForm:
+		if depth >= maxFormDepth {
+			return
+		}
+
+		form, err := res.GetXObjectFormByName(*name)
+		if err != nil {
+			log.Println("invalid form xobject:", *name, err)
+			return
+		}
+
+		content, err := form.GetContentStream()
+		if err != nil {
+			log.Println("invalid form xobject:", *name, err)
+			return
+		}
+
+		p.gsave()
+		if arr, ok := core.GetArray(form.Matrix); ok {
+			if m, err := core.GetNumbersAsFloat(arr.Elements()); err == nil && len(m) == 6 {
+				p.concat(Matrix{m[0], m[1], m[2], m[3], m[4], m[5]})
+			}
+		}
+
+		formRes := form.Resources
+		if formRes == nil {
+			formRes = res
+		}
+		if err := p.run(string(content), formRes, depth+1); err != nil {
+			log.Println("invalid form xobject:", *name, err)
+		}
+		p.grestore()
+	}
+}
+
+// image converts an image xobject to an 8 bit rgb raster, the image fills
+// the unit square of the user space
+func (p *contentInterpreter) image(res *model.PdfPageResources, name core.PdfObjectName) (*Raster, error) {
+	ximg, err := res.GetXObjectImageByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := ximg.ToImage()
+	if err != nil {
+		return nil, err
+	}
+
+	if ximg.ColorSpace != nil {
+		rgb, err := ximg.ColorSpace.ImageToRGB(*img)
+		if err != nil {
+			return nil, err
+		}
+		img = &rgb
+	}
+	if img.BitsPerComponent != 8 {
+		img.Resample(8)
+	}
+
+	w, h := float64(img.Width), float64(img.Height)
+	if w == 0 || h == 0 || len(img.Data) < int(img.Width*img.Height*3) {
+		return nil, errors.New("unsupported image data")
+	}
+
+	return &Raster{
+		Matrix:    Scale(1/w, 1/h).Multiply(Translate(0, 1)),
+		Bounds:    [4]float64{0, 0, w, h},
+		Width:     w,
+		Height:    h,
+		Bits:      8,
+		ImageType: 3,
+		RawData:   img.Data[:img.Width*img.Height*3],
+	}, nil
+}
+
+// the number of colors sampled from a shading function
+const shadingStops = 5
+
+// shading fills the current clip with an axial or radial shading, as a
+// gradient whose unit axis is mapped onto the shading by its matrix. The
+// fill color of the path is the color in the middle, for drawers without
+// gradients. The starting circle of radial shadings and Extend are dropped.
+func (p *contentInterpreter) shading(params []core.PdfObject, res *model.PdfPageResources) {
+	if len(params) == 0 || res == nil {
+		return
+	}
+
+	name, ok := core.GetName(params[0])
+	if !ok {
+		return
+	}
+
+	shading, ok := res.GetShadingByName(*name)
+	if !ok {
+		return
+	}
+
+	gradient := Gradient{Name: string(*name)}
+	var coords, domain *core.PdfObjectArray
+	var functions []model.PdfFunction
+	switch s := shading.GetContext().(type) {
+	case *model.PdfShadingType2:
+		gradient.GradientType = 0
+		coords, domain, functions = s.Coords, s.Domain, s.Function
+	case *model.PdfShadingType3:
+		gradient.GradientType = 1
+		coords, domain, functions = s.Coords, s.Domain, s.Function
+	default:
+		var kind int64
+		if shading.ShadingType != nil {
+			kind = int64(*shading.ShadingType)
+		}
+		log.Println("unsupported shading type:", *name, kind)
+		return
+	}
+
+	var c []float64
+	if coords != nil {
+		c, _ = core.GetNumbersAsFloat(coords.Elements())
+	}
+	switch {
+	case gradient.GradientType == 0 && len(c) == 4:
+		// the x axis onto the segment from x0 y0 to x1 y1
+		dx, dy := c[2]-c[0], c[3]-c[1]
+		gradient.Matrix = Matrix{dx, dy, -dy, dx, c[0], c[1]}
+	case gradient.GradientType == 1 && len(c) == 6:
+		// the unit circle onto the ending circle
+		gradient.Matrix = Matrix{c[5], 0, 0, c[5], c[3], c[4]}
+	default:
+		log.Println("invalid shading coords:", *name)
+		return
+	}
+
+	t0, t1 := 0.0, 1.0
+	if domain != nil {
+		if d, err := core.GetNumbersAsFloat(domain.Elements()); err == nil && len(d) == 2 {
+			t0, t1 = d[0], d[1]
+		}
+	}
+
+	var mid Color
+	for i := 0; i < shadingStops; i++ {
+		offset := float64(i) / (shadingStops - 1)
+		color, ok := shadingColor(shading.ColorSpace, functions, t0+offset*(t1-t0))
+		if !ok {
+			log.Println("invalid shading function:", *name)
+			return
+		}
+		gradient.AddColor(gradientStop(color, offset))
+		if i == shadingStops/2 {
+			mid = color
+		}
+	}
+
+	gs := p.gs.Clone()
+	gs.Fill = mid
+
+	p.d.SetGradient(&gradient)
+	if p.clipPath != nil {
+		for _, fn := range p.clipPath {
+			fn(p.d)
+		}
+	} else {
+		p.pageBox()
+	}
+	p.d.PathRender(AI_Fill, &gs)
+}
+
+// pageBox draws the page box in user space
+func (p *contentInterpreter) pageBox() {
+	inv, ok := p.gs.CTM.Invert()
+	if !ok {
+		return
+	}
+
+	corners := [4][2]float64{
+		{p.box.Llx, p.box.Lly}, {p.box.Urx, p.box.Lly},
+		{p.box.Urx, p.box.Ury}, {p.box.Llx, p.box.Ury},
+	}
+	for i, pt := range corners {
+		x, y := inv.Apply(pt[0], pt[1])
+		if i == 0 {
+			p.d.Moveto(x, y)
+		} else {
+			p.d.Lineto(x, y)
+		}
+	}
+	p.d.ClosePath()
+}
+
+// shadingColor evaluates the functions of a shading at t, one function
+// with n outputs or n functions with one output each
+func shadingColor(cs model.PdfColorspace, functions []model.PdfFunction, t float64) (Color, bool) {
+	if len(functions) == 0 {
+		return Color{}, false
+	}
+
+	var vals []float64
+	for _, fn := range functions {
+		out, err := fn.Evaluate([]float64{t})
+		if err != nil {
+			return Color{}, false
+		}
+		vals = append(vals, out...)
+	}
+	return pdfColor(cs, vals)
+}
+
+// gradientStop returns a gradient color at offset, between 0-1
+func gradientStop(c Color, offset float64) *OffColor {
+	stop := &OffColor{offset: offset, midPoint: 50}
+	switch c.Space {
+	case ColorGray:
+		stop.colorSpace = 0
+		stop.color = []float64{c.Gray}
+	case ColorCMYK:
+		stop.colorSpace = 1
+		stop.color = c.CMYK[:]
+	default:
+		rgb := c.ToRGB()
+		stop.colorSpace = 2
+		stop.color = []float64{float64(rgb[0]) / 255, float64(rgb[1]) / 255, float64(rgb[2]) / 255}
+	}
+	return stop
+}
@@ -0,0 +1,149 @@
+package illustrator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// contentPDF returns a one page pdf without private data, drawing content
+// with the shadings Sh0 axial, Sh1 radial and Sh2 function based
+func contentPDF(content string) []byte {
+	return testPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] /Contents 4 0 R /Resources << /Shading << /Sh0 5 0 R /Sh1 6 0 R /Sh2 7 0 R >> >> >>",
+		streamObj([]byte(content)),
+		"<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [120 120 160 120] /Function << /FunctionType 2 /Domain [0 1] /C0 [1 0 0] /C1 [0 0 1] /N 1 >> >>",
+		"<< /ShadingType 3 /ColorSpace /DeviceGray /Coords [50 50 0 50 50 20] /Function << /FunctionType 2 /Domain [0 1] /C0 [0] /C1 [1] /N 1 >> >>",
+		"<< /ShadingType 1 /ColorSpace /DeviceGray /Function << /FunctionType 2 /Domain [0 1] /C0 [0] /C1 [1] /N 1 >> >>",
+	}, false)
+}
+
+func drawContent(t *testing.T, content string) []Call {
+	t.Helper()
+
+	r, err := NewBytesReader(contentPDF(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := NewRecordingDrawer(nil)
+	if err := r.DrawContent(rec); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Calls
+}
+
+func TestContentShading(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		gradient Gradient
+		path     string // calls of the filled path
+	}{
+		{
+			name:     "axial in a clip",
+			content:  "q 120 110 40 20 re W n /Sh0 sh Q",
+			gradient: Gradient{Name: "Sh0", GradientType: 0, Matrix: Matrix{40, 0, 0, 40, 120, 120}},
+			path:     "Moveto(120, 110) Lineto(160, 110) Lineto(160, 130) Lineto(120, 130) ClosePath() PathRender(fill, gs{fill:rgb[0.5 0 0.5] stroke:cmyk[0 0 0 1] width:1})",
+		},
+		{
+			name:     "radial in the page",
+			content:  "/Sh1 sh",
+			gradient: Gradient{Name: "Sh1", GradientType: 1, Matrix: Matrix{20, 0, 0, 20, 50, 50}},
+			path:     "Moveto(0, 0) Lineto(200, 0) Lineto(200, 200) Lineto(0, 200) ClosePath() PathRender(fill, gs{fill:gray(0.5) stroke:cmyk[0 0 0 1] width:1})",
+		},
+		{
+			name:     "clip restored",
+			content:  "q 0 0 10 10 re W n Q /Sh1 sh",
+			gradient: Gradient{Name: "Sh1", GradientType: 1, Matrix: Matrix{20, 0, 0, 20, 50, 50}},
+			path:     "Moveto(0, 0) Lineto(200, 0) Lineto(200, 200) Lineto(0, 200) ClosePath() PathRender(fill, gs{fill:gray(0.5) stroke:cmyk[0 0 0 1] width:1})",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := drawContent(t, tt.content)
+			i := 0
+			for i < len(calls) && calls[i].Method != "SetGradient" {
+				i++
+			}
+			if i == len(calls) {
+				t.Fatalf("no SetGradient in %v", calls)
+			}
+
+			g := calls[i].Args[0].(Gradient)
+			if g.Name != tt.gradient.Name || g.GradientType != tt.gradient.GradientType || g.Matrix != tt.gradient.Matrix {
+				t.Errorf("got gradient %s type %d matrix %v, want %s type %d matrix %v",
+					g.Name, g.GradientType, g.Matrix, tt.gradient.Name, tt.gradient.GradientType, tt.gradient.Matrix)
+			}
+			if len(g.Colors) != shadingStops {
+				t.Errorf("got %d colors, want %d", len(g.Colors), shadingStops)
+			}
+
+			var path []string
+			for _, c := range calls[i+1:] {
+				path = append(path, c.String())
+				if c.Method == "PathRender" {
+					break
+				}
+			}
+			if got := strings.Join(path, " "); got != tt.path {
+				t.Errorf("got path\n%s\nwant\n%s", got, tt.path)
+			}
+		})
+	}
+}
+
+func TestContentUnsupportedShading(t *testing.T) {
+	var buf bytes.Buffer
+	for _, c := range drawContent(t, "/Sh2 sh") {
+		buf.WriteString(c.String() + "\n")
+	}
+	if strings.Contains(buf.String(), "SetGradient") || strings.Contains(buf.String(), "PathRender") {
+		t.Errorf("function based shading is drawn:\n%s", buf.String())
+	}
+}
+
+func TestContentClip(t *testing.T) {
+	const square = "Moveto Lineto Lineto Lineto ClosePath "
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "clip in q",
+			content: "0 0 10 10 re f q 0 0 5 5 re W* n 1 1 2 2 re f Q 3 3 1 1 re f",
+			want: "SetHeader BeginLayer " + square + "PathRender Group Group " + square + "ClipPath ApplyClip PathRender " +
+				square + "PathRender EndGroup EndGroup " + square + "PathRender EndLayer",
+		},
+		{
+			name:    "clip in the page",
+			content: "0 0 10 10 re f 1 0 0 1 5 5 cm 0 0 5 5 re W n 1 1 2 2 re f",
+			want: "SetHeader BeginLayer " + square + "PathRender PushTransform Group " + square + "ClipPath ApplyClip PathRender " +
+				square + "PathRender EndGroup PopTransform EndLayer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := drawContent(t, tt.content)
+			if got := methods(calls); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+
+			// the rule of W or W* is the rule of the clipping path
+			for i, c := range calls {
+				if c.Method != "PathRender" {
+					continue
+				}
+				clip := calls[i-1].Method == "ApplyClip"
+				evenOdd := c.Args[1].(GraphicsState).EvenOdd
+				if want := clip && strings.Contains(tt.content, "W*"); evenOdd != want {
+					t.Errorf("PathRender %d: even-odd %v, want %v", i, evenOdd, want)
+				}
+			}
+		})
+	}
+}
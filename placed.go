@@ -0,0 +1,61 @@
+package illustrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PlacedArt is a linked or embedded file placed in the document
+type PlacedArt struct {
+	Path     string // link as stored in the document
+	Href     string // link resolved by the reader's LinkResolver
+	Embedded bool
+
+	Matrix Matrix
+	Bounds [4]float64
+}
+
+// LinkResolver maps the path of a placed file to the href to use
+type LinkResolver func(path string) string
+
+// RelativeLinkResolver resolves links to file paths relative to the
+// directory of the ai file, falling back to the file name when the original
+// path is missing. The svg package turns the path into an href relative to
+// the svg file.
+func RelativeLinkResolver(aiPath string) LinkResolver {
+	dir := filepath.Dir(aiPath)
+	return func(path string) string {
+		if len(path) == 0 {
+			return path
+		}
+
+		if filepath.IsAbs(path) {
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+
+		// links saved on mac or windows
+		if i := strings.LastIndexAny(path, `:\/`); i >= 0 {
+			path = path[i+1:]
+		}
+		return filepath.Join(dir, path)
+	}
+}
+
+// XhArgs parses the placed art header: [ a b c d tx ty ] width height flag Xh
+func XhArgs(vals []string) *PlacedArt {
+	if len(vals) < 10 || vals[0] != "[" || vals[7] != "]" {
+		return nil
+	}
+
+	var obj PlacedArt
+	for i := range obj.Matrix {
+		obj.Matrix[i] = toFloat(vals[i+1])
+	}
+	obj.Bounds[2] = toFloat(vals[8])
+	obj.Bounds[3] = toFloat(vals[9])
+
+	return &obj
+}
@@ -0,0 +1,86 @@
+package illustrator
+
+import "io"
+
+// Progress is reported while the private data is read, the bytes of
+// compressed private data are counted before decompression
+type Progress struct {
+	Read  int64  // bytes of private data consumed
+	Total int64  // length of private data, 0 when unknown
+	Layer string // name of the current layer
+}
+
+type ProgressFunc func(p Progress)
+
+// how many bytes are read between two reports
+const progressInterval = 64 * 1024
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// positionReader reports its position in a source of known length, such
+// as the decoder of the private data
+type positionReader interface {
+	position() (read, total int64)
+}
+
+// readerLength returns the unread length of readers such as bytes.Buffer,
+// bytes.Reader and strings.Reader, 0 when unknown
+func readerLength(r io.Reader) int64 {
+	if l, ok := r.(interface{ Len() int }); ok {
+		return int64(l.Len())
+	}
+	return 0
+}
+
+// SetProgress sets the callback reporting the progress of Draw, the total
+// length is known for in-memory readers and Reader.AIPrivateDataReader,
+// or given by SetLength
+func (r *AIReader) SetProgress(fn ProgressFunc) {
+	r.progress = fn
+}
+
+// SetLength sets the length of the private data, for progress reports,
+// the progress then counts the bytes read by the AIReader
+func (r *AIReader) SetLength(n int64) {
+	r.length = n
+	r.source = nil
+}
+
+func (r *AIReader) consumed() int64 {
+	if r.source != nil {
+		n, _ := r.source.position()
+		return n
+	}
+	return r.counter.n - int64(r.Reader.Buffered())
+}
+
+// reportProgress reports the progress, unless less than progressInterval
+// bytes have been read since the last report
+func (r *AIReader) reportProgress(force bool) {
+	if r.progress == nil {
+		return
+	}
+
+	n := r.consumed()
+	if !force && n-r.reported < progressInterval {
+		return
+	}
+
+	r.reported = n
+	r.progress(Progress{Read: n, Total: r.length, Layer: r.layerName})
+}
+
+func (r *AIReader) setLayerName(name string) {
+	r.layerName = name
+	r.reportProgress(true)
+}
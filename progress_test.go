@@ -0,0 +1,35 @@
+package illustrator
+
+import "testing"
+
+func TestProgressPrivateData(t *testing.T) {
+	chunk := encodePS(t, "zlib", []byte(samplePS))
+	r, err := NewBytesReader(aiPDF(splitData(chunk, 2), false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd, err := r.AIPrivateDataReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rd.Close()
+
+	ar, err := NewAIReader(rd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reports []Progress
+	ar.SetProgress(func(p Progress) { reports = append(reports, p) })
+	if err := ar.DrawV2(BaseDrawer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) == 0 {
+		t.Fatal("no progress reported")
+	}
+	last := reports[len(reports)-1]
+	want := int64(len(chunk) - len("%AI12_CompressedData"))
+	if last.Total != want || last.Read != want {
+		t.Errorf("last progress %d of %d, want %d of %d", last.Read, last.Total, want, want)
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 type Raster struct {
-	Matrix            [6]float64
+	Matrix            Matrix
 	Bounds            [4]float64
 	Width             float64
 	Height            float64
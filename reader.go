@@ -2,102 +2,342 @@ package illustrator
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/unidoc/unipdf/v3/core"
 	"github.com/unidoc/unipdf/v3/model"
 )
 
+var (
+	ErrEncrypted       = errors.New("illustrator: the file is encrypted, a password is required")
+	ErrInvalidPassword = errors.New("illustrator: invalid password")
+)
+
 type Reader struct {
 	*model.PdfReader
 
-	page *model.PdfPage
+	src     io.ReadSeeker // the pdf, copied by WritePrivateData
+	closer  io.Closer     // the file of NewFileReader
+	page    *model.PdfPage
+	pageNum int
+}
+
+type ReaderOption struct {
+	Password string // user or owner password of encrypted files
+}
+
+// WithPassword sets the password of encrypted files, permission-protected
+// files open without a password
+func WithPassword(password string) func(*ReaderOption) {
+	return func(opt *ReaderOption) {
+		opt.Password = password
+	}
 }
 
-func NewReader(r io.ReadSeeker) (*Reader, error) {
+func NewReader(r io.ReadSeeker, options ...func(*ReaderOption)) (*Reader, error) {
+	var opt ReaderOption
+	for _, o := range options {
+		o(&opt)
+	}
+
 	reader, err := model.NewPdfReader(r)
 	if err != nil {
 		return nil, err
 	}
 
-	page, err := reader.GetPage(1)
+	encrypted, err := reader.IsEncrypted()
+	if err != nil {
+		return nil, err
+	}
+
+	if encrypted {
+		// the empty password is tried as well
+		ok, err := reader.Decrypt([]byte(opt.Password))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if len(opt.Password) == 0 {
+				return nil, ErrEncrypted
+			}
+			return nil, ErrInvalidPassword
+		}
+	}
+
+	page, num, err := findIllustratorPage(reader)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Reader{
 		PdfReader: reader,
+		src:       r,
 		page:      page,
+		pageNum:   num,
 	}, nil
 }
 
-func NewFileReader(inputPath string) (*Reader, error) {
+// NewReaderAt reads size bytes of r, e.g. a range-readable blob
+func NewReaderAt(r io.ReaderAt, size int64, options ...func(*ReaderOption)) (*Reader, error) {
+	return NewReader(io.NewSectionReader(r, 0, size), options...)
+}
+
+// NewBytesReader reads the pdf in data
+func NewBytesReader(data []byte, options ...func(*ReaderOption)) (*Reader, error) {
+	return NewReader(bytes.NewReader(data), options...)
+}
+
+// NewFileReader reads the pdf at inputPath, the file is kept open for
+// WritePrivateData until Close
+func NewFileReader(inputPath string, options ...func(*ReaderOption)) (*Reader, error) {
 	file, err := os.Open(inputPath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	return NewReader(file)
+	reader, err := NewReader(file, options...)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader.closer = file
+	return reader, nil
+}
+
+// Close closes the file of NewFileReader, it does nothing for the other
+// readers
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	err := r.closer.Close()
+	r.closer = nil
+	return err
 }
 
+// GetIllustrator returns the illustrator piece info of the page, nil when
+// the file is saved without illustrator editing capabilities
 func (r *Reader) GetIllustrator() *core.PdfIndirectObject {
-	pieceInfo, _ := r.page.PieceInfo.(*core.PdfObjectDictionary)
+	return pageIllustrator(r.page)
+}
 
-	illustrator, _ := pieceInfo.Get("Illustrator").(*core.PdfIndirectObject)
+func pageIllustrator(page *model.PdfPage) *core.PdfIndirectObject {
+	pieceInfo, _ := core.GetDict(page.PieceInfo)
+	if pieceInfo == nil {
+		return nil
+	}
 
-	return illustrator
+	switch obj := pieceInfo.Get("Illustrator").(type) {
+	case *core.PdfIndirectObject:
+		return obj
+	case *core.PdfObjectDictionary:
+		// inline, the object number is 0
+		return &core.PdfIndirectObject{PdfObject: obj}
+	}
+	return nil
 }
 
-func (r *Reader) getPrivate() *core.PdfIndirectObject {
-	illustrator := r.GetIllustrator().PdfObject.(*core.PdfObjectDictionary)
+// getPrivate returns the private dictionary, nil when missing
+func (r *Reader) getPrivate() *core.PdfObjectDictionary {
+	illustrator := r.GetIllustrator()
+	if illustrator == nil {
+		return nil
+	}
 
-	return illustrator.Get("Private").(*core.PdfIndirectObject)
+	dict, _ := core.GetDict(illustrator.PdfObject)
+	if dict == nil {
+		return nil
+	}
+
+	private, _ := core.GetDict(dict.Get("Private"))
+	return private
 }
 
 func (r *Reader) GetAiMetaData() *core.PdfObjectStream {
-	objDict, _ := r.getPrivate().PdfObject.(*core.PdfObjectDictionary)
+	objDict := r.getPrivate()
+	if objDict == nil {
+		return nil
+	}
 
 	stream, _ := objDict.Get("AIMetaData").(*core.PdfObjectStream)
 	return stream
 }
 
 func (r *Reader) GetAIPrivateData() ([]byte, error) {
-	objDict, _ := r.getPrivate().PdfObject.(*core.PdfObjectDictionary)
-
-	var handle CompressHandle
-	length := len(objDict.Keys())
-	for i := 0; i < length; i++ {
-		key := fmt.Sprintf("AIPrivateData%d", i+1)
-		stream := objDict.Get(core.PdfObjectName(key))
-		if stream == nil {
-			break
+	rd, err := r.AIPrivateDataReader()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+
+	return io.ReadAll(rd)
+}
+
+// AIPrivateDataReader returns the decompressed private data as a stream,
+// the AIPrivateDataN streams are chained into a streaming decoder without
+// being copied
+func (r *Reader) AIPrivateDataReader() (io.ReadCloser, error) {
+	objDict := r.getPrivate()
+	if objDict == nil {
+		return nil, ErrNoPrivateData
+	}
+
+	p := walkPrivateData(objDict)
+	if len(p.chunks) == 0 {
+		return nil, ErrNoPrivateData
+	}
+	first := p.chunks[p.start].index
+	for _, n := range p.missing {
+		if n > first {
+			return nil, fmt.Errorf("%w: AIPrivateData%d of %d is missing", ErrCorruptPrivateData, n, p.max())
+		}
+	}
+	for _, c := range p.used() {
+		if c.err != nil {
+			return nil, c.err
+		}
+	}
+	if p.encErr != nil {
+		return nil, p.encErr
+	}
+
+	rd, length := p.compressed()
+	return newPrivateDecoder(p.enc, rd, length)
+}
+
+// privateChunk is an AIPrivateDataN stream
+type privateChunk struct {
+	index  int // N
+	stream *core.PdfObjectStream
+	data   []byte // after the pdf filters
+	err    error  // error of the pdf filters
+}
+
+// privateData is the walk of the AIPrivateDataN streams of a private
+// dictionary, shared by the reader, the writer and Inspect
+type privateData struct {
+	chunks  []privateChunk       // streams in order of N
+	missing []int                // missing N up to the largest N
+	keys    []core.PdfObjectName // every AIPrivateData key, streams or not
+	start   int                  // index in chunks of the stream starting with the encoding header
+	enc     *Encoding            // nil for uncompressed postscript
+	encErr  error                // no stream has a known encoding
+}
+
+// walkPrivateData reads the AIPrivateDataN streams of dict and finds the
+// first one starting with an encoding header, the streams before it are
+// skipped
+func walkPrivateData(dict *core.PdfObjectDictionary) *privateData {
+	p := &privateData{}
+	streams := map[int]*core.PdfObjectStream{}
+	max := 0
+	for _, key := range dict.Keys() {
+		name := string(key)
+		if !strings.HasPrefix(name, "AIPrivateData") {
+			continue
 		}
+		p.keys = append(p.keys, key)
 
-		pdfObj := stream.(*core.PdfObjectStream)
-		if bytes.HasPrefix(pdfObj.Stream, []byte("%AI12_CompressedData")) {
-			handle = &ZlibCompress{}
-			handle.Write(pdfObj.Stream[20:])
+		n, err := strconv.Atoi(name[len("AIPrivateData"):])
+		if err != nil || n < 1 {
 			continue
 		}
 
-		if bytes.HasPrefix(pdfObj.Stream, []byte("%AI24_ZStandard_Data")) {
-			handle = &ZStdCompress{}
-			handle.Write(pdfObj.Stream[20:])
+		if stream, ok := core.GetStream(dict.Get(key)); ok {
+			streams[n] = stream
+			if n > max {
+				max = n
+			}
+		}
+	}
+
+	for i := 1; i <= max; i++ {
+		stream, ok := streams[i]
+		if !ok {
+			p.missing = append(p.missing, i)
 			continue
 		}
 
-		if handle != nil {
-			handle.Write(pdfObj.Stream)
+		c := privateChunk{index: i, stream: stream}
+		c.data, c.err = streamData(stream)
+		p.chunks = append(p.chunks, c)
+	}
+
+	for i, c := range p.chunks {
+		if enc, ok := matchEncoding(c.data); ok {
+			p.start, p.enc = i, &enc
+			return p
+		}
+	}
+	if len(p.chunks) > 0 {
+		p.encErr = privateEncoding(p.chunks[0].data)
+	}
+	return p
+}
+
+// used returns the streams from the encoding header on
+func (p *privateData) used() []privateChunk {
+	return p.chunks[p.start:]
+}
+
+// skipped returns N of the streams before the encoding header
+func (p *privateData) skipped() []int {
+	var skipped []int
+	for _, c := range p.chunks[:p.start] {
+		skipped = append(skipped, c.index)
+	}
+	return skipped
+}
+
+// max returns the largest N
+func (p *privateData) max() int {
+	max := 0
+	if l := len(p.chunks); l > 0 {
+		max = p.chunks[l-1].index
+	}
+	if l := len(p.missing); l > 0 && p.missing[l-1] > max {
+		max = p.missing[l-1]
+	}
+	return max
+}
+
+// compressed chains the data of the streams after the encoding header,
+// and returns its length
+func (p *privateData) compressed() (io.Reader, int64) {
+	chunks := p.used()
+	readers := make([]io.Reader, len(chunks))
+	var length int64
+	for i, c := range chunks {
+		data := c.data
+		if i == 0 && p.enc != nil {
+			data = data[len(p.enc.Header):]
 		}
+		readers[i] = bytes.NewReader(data)
+		length += int64(len(data))
 	}
+	return io.MultiReader(readers...), length
+}
+
+// privateEncoding checks that the first stream of private data without
+// encoding header is uncompressed postscript
+func privateEncoding(data []byte) error {
+	if bytes.HasPrefix(data, []byte("%!PS-Adobe")) || bytes.HasPrefix(data, []byte("%%")) {
+		return nil
+	}
+	return ErrUnsupportedCompression
+}
 
-	if handle != nil {
-		return handle.Decompress()
+// streamData returns the data of a stream, decoded when it has filters
+func streamData(stream *core.PdfObjectStream) ([]byte, error) {
+	if stream.Get("Filter") == nil {
+		return stream.Stream, nil
 	}
-	return nil, fmt.Errorf("no valid data found")
+	return core.DecodeStream(stream)
 }
 
 func (r *Reader) AsSvg() error {
@@ -0,0 +1,295 @@
+package illustrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Call is a recorded Drawer call, pointer arguments are copied by value
+type Call struct {
+	Method string        `json:"method"`
+	Args   []interface{} `json:"args,omitempty"`
+	Line   int           `json:"line,omitempty"` // source line of the private data
+}
+
+func (c Call) String() string {
+	var w strings.Builder
+	if c.Line > 0 {
+		w.WriteString(strconv.Itoa(c.Line))
+		w.WriteString(": ")
+	}
+
+	w.WriteString(c.Method)
+	w.WriteByte('(')
+	for i, arg := range c.Args {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		w.WriteString(formatArg(arg))
+	}
+	w.WriteByte(')')
+	return w.String()
+}
+
+func formatArg(arg interface{}) string {
+	switch v := arg.(type) {
+	case float64:
+		return formatFloat(v)
+	case string:
+		return strconv.Quote(v)
+	case PathOp:
+		var op string
+		if v&AI_Fill > 0 {
+			op += "fill"
+		}
+		if v&AI_Stroke > 0 {
+			if len(op) > 0 {
+				op += "|"
+			}
+			op += "stroke"
+		}
+		if len(op) == 0 {
+			op = "none"
+		}
+		return op
+	case Color:
+		switch v.Space {
+		case ColorGray:
+			return fmt.Sprintf("gray(%s)", formatFloat(v.Gray))
+		case ColorRGB:
+			return fmt.Sprintf("rgb%v", v.RGB)
+		case ColorCMYK:
+			return fmt.Sprintf("cmyk%v", v.CMYK)
+		}
+		return fmt.Sprintf("spot(%q %s)", v.Name, formatFloat(v.Tint))
+	case GraphicsState:
+		return fmt.Sprintf("gs{fill:%s stroke:%s width:%s}",
+			formatArg(v.Fill), formatArg(v.Stroke), formatFloat(v.LineWidth))
+	case AILayer:
+		return strconv.Quote(v.Name)
+	case Gradient:
+		return strconv.Quote(v.Name)
+	case Raster:
+		return fmt.Sprintf("raster{%sx%s %d bytes}", formatFloat(v.Width), formatFloat(v.Height), len(v.RawData))
+	case Mesh:
+		return fmt.Sprintf("mesh{%dx%d}", v.Rows, v.Cols)
+	case PlacedArt:
+		return fmt.Sprintf("placed{%q}", v.Path)
+	case Matrix:
+		vals := make([]string, len(v))
+		for i, f := range v {
+			vals[i] = formatFloat(f)
+		}
+		return "[" + strings.Join(vals, " ") + "]"
+	case Guide:
+		return fmt.Sprintf("guide{%d points}", len(v.Points))
+	case []Effect:
+		names := make([]string, len(v))
+		for i, e := range v {
+			names[i] = strconv.Quote(e.Name)
+		}
+		return "[" + strings.Join(names, " ") + "]"
+	}
+	return fmt.Sprint(arg)
+}
+
+// RecordingDrawer is a DrawerV2 recording every call, for debugging and
+// golden tests
+type RecordingDrawer struct {
+	Calls []Call
+
+	line func() int
+}
+
+// NewRecordingDrawer returns a recorder, line is optional and returns the
+// current source line, e.g. AIReader.Line
+func NewRecordingDrawer(line func() int) *RecordingDrawer {
+	return &RecordingDrawer{line: line}
+}
+
+func (r *RecordingDrawer) record(method string, args ...interface{}) {
+	c := Call{Method: method, Args: args}
+	if r.line != nil {
+		c.Line = r.line()
+	}
+	r.Calls = append(r.Calls, c)
+}
+
+// WriteText writes one call per line
+func (r *RecordingDrawer) WriteText(w io.Writer) error {
+	for _, c := range r.Calls {
+		if _, err := fmt.Fprintln(w, c.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the calls as json, for inspection only: decoding turns
+// the arguments into maps and float64, which Replay does not accept
+func (r *RecordingDrawer) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Calls)
+}
+
+func (r *RecordingDrawer) SetHeader(header *AIHeader) { r.record("SetHeader", *header) }
+func (r *RecordingDrawer) BeginLayer(l *AILayer)      { r.record("BeginLayer", *l) }
+func (r *RecordingDrawer) SetLayerName(name string)   { r.record("SetLayerName", name) }
+func (r *RecordingDrawer) EndLayer()                  { r.record("EndLayer") }
+func (r *RecordingDrawer) Group()                     { r.record("Group") }
+func (r *RecordingDrawer) EndGroup()                  { r.record("EndGroup") }
+func (r *RecordingDrawer) SetGroupAttr()              { r.record("SetGroupAttr") }
+func (r *RecordingDrawer) ClosePath()                 { r.record("ClosePath") }
+
+func (r *RecordingDrawer) PathRender(op PathOp, gs *GraphicsState) {
+	r.record("PathRender", op, gs.Clone())
+}
+
+func (r *RecordingDrawer) Moveto(x, y float64) { r.record("Moveto", x, y) }
+func (r *RecordingDrawer) Lineto(x, y float64) { r.record("Lineto", x, y) }
+
+func (r *RecordingDrawer) Curveto1(x0, y0, x1, y1 float64) {
+	r.record("Curveto1", x0, y0, x1, y1)
+}
+
+func (r *RecordingDrawer) Curveto2(x1, y1, x2, y2 float64) {
+	r.record("Curveto2", x1, y1, x2, y2)
+}
+
+func (r *RecordingDrawer) Curveto(x0, y0, x1, y1, x2, y2 float64) {
+	r.record("Curveto", x0, y0, x1, y1, x2, y2)
+}
+
+func (r *RecordingDrawer) ClipPath()                   { r.record("ClipPath") }
+func (r *RecordingDrawer) ApplyClip()                  { r.record("ApplyClip") }
+func (r *RecordingDrawer) Guide(g *Guide)              { r.record("Guide", *g) }
+func (r *RecordingDrawer) CompoundPath()               { r.record("CompoundPath") }
+func (r *RecordingDrawer) EndCompoundPath()            { r.record("EndCompoundPath") }
+func (r *RecordingDrawer) SetColor(op PathOp, c Color) { r.record("SetColor", op, c) }
+func (r *RecordingDrawer) SetOpacity(opacity float64)  { r.record("SetOpacity", opacity) }
+
+func (r *RecordingDrawer) SetDash(dash []float64, phase float64) {
+	r.record("SetDash", append([]float64(nil), dash...), phase)
+}
+
+func (r *RecordingDrawer) SetFlat(flatness float64)    { r.record("SetFlat", flatness) }
+func (r *RecordingDrawer) SetLineCap(v LineCap)        { r.record("SetLineCap", v) }
+func (r *RecordingDrawer) SetLineJoin(v LineJoin)      { r.record("SetLineJoin", v) }
+func (r *RecordingDrawer) SetLineWidth(v float64)      { r.record("SetLineWidth", v) }
+func (r *RecordingDrawer) SetMiterLimit(v float64)     { r.record("SetMiterLimit", v) }
+func (r *RecordingDrawer) DefGradient(g *Gradient)     { r.record("DefGradient", *g) }
+func (r *RecordingDrawer) SetGradient(g *Gradient)     { r.record("SetGradient", *g) }
+func (r *RecordingDrawer) SetRaster(obj *Raster)       { r.record("SetRaster", *obj) }
+func (r *RecordingDrawer) PlacedArt(obj *PlacedArt)    { r.record("PlacedArt", *obj) }
+func (r *RecordingDrawer) Mesh(m *Mesh)                { r.record("Mesh", *m) }
+func (r *RecordingDrawer) SetEffects(effects []Effect) { r.record("SetEffects", effects) }
+func (r *RecordingDrawer) PushTransform(m Matrix)      { r.record("PushTransform", m) }
+func (r *RecordingDrawer) PopTransform()               { r.record("PopTransform") }
+
+// Replay calls d with the recorded calls of rec
+func Replay(rec *RecordingDrawer, d DrawerV2) error {
+	for _, c := range rec.Calls {
+		if err := replay(c, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replay(c Call, d DrawerV2) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("replay %s: invalid arguments %v", c.Method, c.Args)
+		}
+	}()
+
+	f := func(i int) float64 { return c.Args[i].(float64) }
+	switch c.Method {
+	case "SetHeader":
+		header := c.Args[0].(AIHeader)
+		d.SetHeader(&header)
+	case "BeginLayer":
+		layer := c.Args[0].(AILayer)
+		d.BeginLayer(&layer)
+	case "SetLayerName":
+		d.SetLayerName(c.Args[0].(string))
+	case "EndLayer":
+		d.EndLayer()
+	case "Group":
+		d.Group()
+	case "EndGroup":
+		d.EndGroup()
+	case "SetGroupAttr":
+		d.SetGroupAttr()
+	case "ClosePath":
+		d.ClosePath()
+	case "PathRender":
+		gs := c.Args[1].(GraphicsState)
+		d.PathRender(c.Args[0].(PathOp), &gs)
+	case "Moveto":
+		d.Moveto(f(0), f(1))
+	case "Lineto":
+		d.Lineto(f(0), f(1))
+	case "Curveto1":
+		d.Curveto1(f(0), f(1), f(2), f(3))
+	case "Curveto2":
+		d.Curveto2(f(0), f(1), f(2), f(3))
+	case "Curveto":
+		d.Curveto(f(0), f(1), f(2), f(3), f(4), f(5))
+	case "ClipPath":
+		d.ClipPath()
+	case "ApplyClip":
+		d.ApplyClip()
+	case "Guide":
+		g := c.Args[0].(Guide)
+		d.Guide(&g)
+	case "CompoundPath":
+		d.CompoundPath()
+	case "EndCompoundPath":
+		d.EndCompoundPath()
+	case "SetColor":
+		d.SetColor(c.Args[0].(PathOp), c.Args[1].(Color))
+	case "SetOpacity":
+		d.SetOpacity(f(0))
+	case "SetDash":
+		d.SetDash(c.Args[0].([]float64), f(1))
+	case "SetFlat":
+		d.SetFlat(f(0))
+	case "SetLineCap":
+		d.SetLineCap(c.Args[0].(LineCap))
+	case "SetLineJoin":
+		d.SetLineJoin(c.Args[0].(LineJoin))
+	case "SetLineWidth":
+		d.SetLineWidth(f(0))
+	case "SetMiterLimit":
+		d.SetMiterLimit(f(0))
+	case "DefGradient":
+		g := c.Args[0].(Gradient)
+		d.DefGradient(&g)
+	case "SetGradient":
+		g := c.Args[0].(Gradient)
+		d.SetGradient(&g)
+	case "SetRaster":
+		obj := c.Args[0].(Raster)
+		d.SetRaster(&obj)
+	case "PlacedArt":
+		obj := c.Args[0].(PlacedArt)
+		d.PlacedArt(&obj)
+	case "Mesh":
+		m := c.Args[0].(Mesh)
+		d.Mesh(&m)
+	case "SetEffects":
+		d.SetEffects(c.Args[0].([]Effect))
+	case "PushTransform":
+		d.PushTransform(c.Args[0].(Matrix))
+	case "PopTransform":
+		d.PopTransform()
+	default:
+		return fmt.Errorf("replay: unknown method %s", c.Method)
+	}
+	return nil
+}
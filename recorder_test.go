@@ -0,0 +1,71 @@
+package illustrator
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	for _, file := range []string{"testdata/path.ps", "testdata/group.ps", "testdata/setup.ps", "testdata/art.ps"} {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, _ := NewAIReader(bytes.NewReader(src))
+		rec := NewRecordingDrawer(nil)
+		if err := r.DrawV2(rec); err != nil {
+			t.Fatal(err)
+		}
+
+		replayed := NewRecordingDrawer(nil)
+		if err := Replay(rec, replayed); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if !reflect.DeepEqual(rec.Calls, replayed.Calls) {
+			t.Errorf("%s: replayed calls differ", file)
+		}
+	}
+}
+
+func TestReplayInvalidArgs(t *testing.T) {
+	rec := &RecordingDrawer{Calls: []Call{{Method: "Moveto", Args: []interface{}{"x", 1.0}}}}
+	if err := Replay(rec, BaseDrawer{}); err == nil {
+		t.Error("got no error for invalid arguments")
+	}
+
+	rec = &RecordingDrawer{Calls: []Call{{Method: "Unknown"}}}
+	if err := Replay(rec, BaseDrawer{}); err == nil {
+		t.Error("got no error for an unknown method")
+	}
+}
+
+func TestRecordLineAfterRaster(t *testing.T) {
+	src, err := os.ReadFile("testdata/art.ps")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := NewAIReader(bytes.NewReader(src))
+	rec := NewRecordingDrawer(r.Line)
+	if err := r.DrawV2(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// the path after the raster block starts at line 47
+	var raster bool
+	for _, c := range rec.Calls {
+		if c.Method == "SetRaster" {
+			raster = true
+		}
+		if raster && c.Method == "Moveto" {
+			if c.Line != 47 {
+				t.Errorf("Moveto after the raster at line %d, want 47", c.Line)
+			}
+			return
+		}
+	}
+	t.Error("no Moveto after the raster")
+}
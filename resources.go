@@ -0,0 +1,91 @@
+package illustrator
+
+var (
+	AI8_BeginBrushPattern = []byte("%AI8_BeginBrushPattern")
+	AI8_EndBrushPattern   = []byte("%AI8_EndBrushPattern")
+	AI9_BeginArtStyles    = []byte("%AI9_BeginArtStyles")
+	AI9_EndArtStyles      = []byte("%AI9_EndArtStyles")
+)
+
+type BrushType int8
+
+const (
+	BrushCalligraphic BrushType = iota
+	BrushScatter
+	BrushArt
+	BrushPattern
+	BrushBristle
+)
+
+func (t BrushType) String() string {
+	switch t {
+	case BrushCalligraphic:
+		return "calligraphic"
+	case BrushScatter:
+		return "scatter"
+	case BrushArt:
+		return "art"
+	case BrushPattern:
+		return "pattern"
+	case BrushBristle:
+		return "bristle"
+	}
+	return "unknown"
+}
+
+type Brush struct {
+	Name string
+	Type BrushType
+
+	Params Dict // raw brush dictionary
+}
+
+// ArtStyle is a graphic style
+type ArtStyle struct {
+	Name string
+
+	Params Dict // raw art style dictionary
+}
+
+// Resources lists the brushes and graphic styles defined in the setup section
+type Resources struct {
+	Brushes   []Brush
+	ArtStyles []ArtStyle
+}
+
+func (res *Resources) Brush(name string) *Brush {
+	for i := range res.Brushes {
+		if res.Brushes[i].Name == name {
+			return &res.Brushes[i]
+		}
+	}
+	return nil
+}
+
+func (res *Resources) ArtStyle(name string) *ArtStyle {
+	for i := range res.ArtStyles {
+		if res.ArtStyles[i].Name == name {
+			return &res.ArtStyles[i]
+		}
+	}
+	return nil
+}
+
+func (res *Resources) addBrushes(dicts []Dict) {
+	for _, d := range dicts {
+		res.Brushes = append(res.Brushes, Brush{
+			Name:   d.String("Name"),
+			Type:   BrushType(d.Int("Type")),
+			Params: d,
+		})
+	}
+}
+
+func (res *Resources) addArtStyles(dicts []Dict) {
+	for _, d := range dicts {
+		res.ArtStyles = append(res.ArtStyles, ArtStyle{
+			Name:   d.String("Name"),
+			Params: d,
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package illustrator
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 func toInt(s string) int {
 	v, _ := strconv.ParseInt(s, 10, 0)
@@ -23,9 +26,66 @@ func toFloat(s string) float64 {
 }
 
 func toFloatSlice(vals []string) []float64 {
+	if vals == nil {
+		return nil
+	}
 	r := make([]float64, len(vals))
 	for i := range vals {
 		r[i], _ = strconv.ParseFloat(vals[i], 0)
 	}
 	return r
 }
+
+// toString decodes a postscript string literal such as (Layer \(1\)).
+func toString(s string) string {
+	if len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+
+	var w strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			w.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			w.WriteByte('\n')
+		case 'r':
+			w.WriteByte('\r')
+		case 't':
+			w.WriteByte('\t')
+		case 'b':
+			w.WriteByte('\b')
+		case 'f':
+			w.WriteByte('\f')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// up to three octal digits
+			var c, n int
+			for n < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7' {
+				c = c*8 + int(s[i]-'0')
+				i++
+				n++
+			}
+			i--
+			w.WriteByte(byte(c))
+		case '\r', '\n':
+			// line continuation
+		default:
+			w.WriteByte(s[i])
+		}
+	}
+
+	return w.String()
+}
+
+// formatFloat formats v in the shortest form without an exponent
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
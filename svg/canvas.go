@@ -1,11 +1,17 @@
 package svg
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"log"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/fpagyu/illustrator"
 
@@ -22,8 +28,11 @@ const (
 
 type SVG struct {
 	viewBox  [4]int
-	layers   []SvgNode   // 对应ai文件的层
-	gradient SvgGradient // 渐变
+	layers   []SvgNode           // 对应ai文件的层
+	gradient SvgGradient         // 渐变
+	filters  []SvgFilter         // 滤镜, 对应ai的实时效果
+	guides   []illustrator.Guide // 参考线
+	effects  string              // 待应用到下一个元素的滤镜
 
 	group        *SvgGroup  // 当前所在的group
 	currentPoint [2]float64 // 当前坐标
@@ -99,6 +108,7 @@ func (svg *SVG) Group() {
 
 	parent := svg.group.parent
 	parent.childs = append(parent.childs, svg.group)
+	svg.applyEffects(svg.group)
 }
 
 func (svg *SVG) EndGroup() {
@@ -185,6 +195,11 @@ func (svg *SVG) ClosePath() {
 }
 
 func (svg *SVG) PathRender(t illustrator.PathOp) {
+	svg.pathRender(t, svg.styles)
+}
+
+// pathRender 绘制路径, styles为路径的样式
+func (svg *SVG) pathRender(t illustrator.PathOp, styles StyleBuild) {
 	if svg.path.Len() == 0 || t == 0 {
 		// no fill no stroke
 		return
@@ -205,14 +220,18 @@ func (svg *SVG) PathRender(t illustrator.PathOp) {
 
 	path.pathOp |= t
 	path.d = svg.path.String()
+	if svg.path.IsCompound() || !svg.path.IsClip() {
+		// 剪切路径不使用滤镜, 留给下一个元素
+		svg.applyEffects(path)
+	}
 	isFill := (t & illustrator.AI_Fill) > 0
 	isStroke := (t & illustrator.AI_Stroke) > 0
 	if isFill && isStroke {
-		path.SetStyle(svg.styles.styles())
+		path.SetStyle(styles.styles())
 	} else if isFill {
-		path.SetStyle(svg.styles.fill())
+		path.SetStyle(styles.fill())
 	} else if isStroke {
-		path.SetStyle(svg.styles.stroke())
+		path.SetStyle(styles.stroke())
 	}
 }
 
@@ -242,81 +261,132 @@ func (svg *SVG) ApplyClip() {
 }
 
 func (svg *SVG) SetRGB(t illustrator.PathOp, rgb [3]uint8) {
-	if (t & illustrator.AI_Fill) == illustrator.AI_Fill {
-		// set fill
-		svg.setStyle("fill", fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2]))
-		return
+	svg.setColor(t, illustrator.RGBColor(float64(rgb[0])/255, float64(rgb[1])/255, float64(rgb[2])/255))
+}
+
+func (svg *SVG) SetCMYK(t illustrator.PathOp, cmyk [4]float64) {
+	svg.setColor(t, illustrator.CMYKColor(cmyk[0], cmyk[1], cmyk[2], cmyk[3]))
+}
+
+func (svg *SVG) SetOpacity(opacity string) {
+	if v, err := strconv.ParseFloat(opacity, 64); err == nil {
+		svg.setOpacity(v)
 	}
+}
 
-	if (t & illustrator.AI_Stroke) == illustrator.AI_Stroke {
-		// set stroke
-		svg.setStyle("stroke", fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2]))
-		return
+func (svg *SVG) SetDash() {}
+func (svg *SVG) SetFlat() {}
+func (svg *SVG) SetLineCap(v string) {
+	if n, err := strconv.Atoi(v); err == nil {
+		svg.setLineCap(illustrator.LineCap(n))
 	}
 }
 
-func (svg *SVG) SetCMYK(t illustrator.PathOp, cmyk [4]float64) {
-	c, m := cmyk[0], cmyk[1]
-	y, k := cmyk[2], cmyk[3]
-	r := (1 - c) * (1 - k)
-	g := (1 - m) * (1 - k)
-	b := (1 - y) * (1 - k)
+func (svg *SVG) SetLineJoin(v string) {
+	if n, err := strconv.Atoi(v); err == nil {
+		svg.setLineJoin(illustrator.LineJoin(n))
+	}
+}
+
+func (svg *SVG) SetLineWidth(v string) {
+	if w, err := strconv.ParseFloat(v, 64); err == nil {
+		svg.setLineWidth(w)
+	}
+}
 
+func (svg *SVG) SetMiterLimit(v string) {
+	if l, err := strconv.ParseFloat(v, 64); err == nil {
+		svg.setMiterLimit(l)
+	}
+}
+
+func (svg *SVG) setColor(t illustrator.PathOp, c illustrator.Color) {
 	if (t & illustrator.AI_Fill) == illustrator.AI_Fill {
 		// set fill
-		svg.setStyle("fill", fmt.Sprintf("#%02X%02X%02X", r, g, b))
+		svg.setStyle("fill", colorHex(c))
 		return
 	}
 
 	if (t & illustrator.AI_Stroke) == illustrator.AI_Stroke {
 		// set stroke
-		svg.setStyle("stroke", fmt.Sprintf("#%02X%02X%02X", r, g, b))
+		svg.setStyle("stroke", colorHex(c))
 		return
 	}
 }
 
-func (svg *SVG) SetOpacity(opacity string) {
-	if opacity == "1" {
+func (svg *SVG) setOpacity(opacity float64) {
+	if opacity == 1 {
 		svg.delStyle("opacity")
 	} else {
-		svg.setStyle("opacity", opacity)
+		svg.setStyle("opacity", Float(opacity))
 	}
 }
 
-func (svg *SVG) SetDash() {}
-func (svg *SVG) SetFlat() {}
-func (svg *SVG) SetLineCap(v string) {
+func (svg *SVG) setLineCap(v illustrator.LineCap) {
 	switch v {
-	case "0":
+	case illustrator.ButtCap:
 		svg.delStyle("stroke-linecap")
-	case "1":
+	case illustrator.RoundCap:
 		svg.setStyle("stroke-linecap", "round")
-	case "2":
+	case illustrator.SquareCap:
 		svg.setStyle("stroke-linecap", "square")
 	}
 }
 
-func (svg *SVG) SetLineJoin(v string) {
+func (svg *SVG) setLineJoin(v illustrator.LineJoin) {
 	switch v {
-	case "0": // miter
+	case illustrator.MiterJoin:
 		svg.delStyle("stroke-linejoin")
-	case "1":
+	case illustrator.RoundJoin:
 		svg.setStyle("stroke-linejoin", "round")
-	case "2":
+	case illustrator.BevelJoin:
 		svg.setStyle("stroke-linejoin", "bevel")
 	}
 }
 
-func (svg *SVG) SetLineWidth(v string) {
-	// svg.styles["stroke-width"] = v
-	svg.setStyle("stroke-width", v)
+func (svg *SVG) setLineWidth(v float64) {
+	svg.setStyle("stroke-width", Float(v))
 }
 
-func (svg *SVG) SetMiterLimit(v string) {
-	// svg.styles["stroke-miterlimit"] = v
-	svg.setStyle("stroke-miterlimit", v)
+func (svg *SVG) setMiterLimit(v float64) {
+	svg.setStyle("stroke-miterlimit", Float(v))
+}
+
+// setClipRule 设置当前剪切路径的规则
+func (svg *SVG) setClipRule(rule string) {
+	if svg.path.IsCompound() {
+		svg.path.compoundPath.clipRule = rule
+	} else if svg.group != nil && len(svg.group.clips) > 0 {
+		svg.group.clips[len(svg.group.clips)-1].clipRule = rule
+	}
+}
+
+// SVGV2 是SVG的illustrator.DrawerV2, 接收类型化的样式, 路径按图形状态绘制
+type SVGV2 struct {
+	*SVG
 }
 
+// V2 返回绘制到svg的illustrator.DrawerV2
+func (svg *SVG) V2() *SVGV2 {
+	return &SVGV2{svg}
+}
+
+func (v *SVGV2) PathRender(t illustrator.PathOp, gs *illustrator.GraphicsState) {
+	if gs.EvenOdd && v.path.IsClip() {
+		v.setClipRule("evenodd")
+	}
+	v.pathRender(t, stateStyle(gs))
+}
+
+func (v *SVGV2) SetColor(t illustrator.PathOp, c illustrator.Color) { v.setColor(t, c) }
+func (v *SVGV2) SetOpacity(opacity float64)                         { v.setOpacity(opacity) }
+func (v *SVGV2) SetDash(dash []float64, phase float64)              {}
+func (v *SVGV2) SetFlat(flatness float64)                           {}
+func (v *SVGV2) SetLineCap(c illustrator.LineCap)                   { v.setLineCap(c) }
+func (v *SVGV2) SetLineJoin(j illustrator.LineJoin)                 { v.setLineJoin(j) }
+func (v *SVGV2) SetLineWidth(w float64)                             { v.setLineWidth(w) }
+func (v *SVGV2) SetMiterLimit(l float64)                            { v.setMiterLimit(l) }
+
 func (svg *SVG) SetGradient(g *illustrator.Gradient) {
 	if g.Flag == 2 { // disable rending
 		return
@@ -342,19 +412,17 @@ func (svg *SVG) SetRaster(raster *illustrator.Raster) {
 	image := SvgImage{
 		id:     "<Image>",
 		parent: svg.group,
-		width:  int(raster.Width),
-		height: int(raster.Height),
-		matrix: raster.Matrix,
+		width:  raster.Width,
+		height: raster.Height,
+		matrix: svg.imageMatrix(raster.Matrix),
 		styles: svg.styles.nofillstroke(),
 	}
 
-	image.matrix[4] = image.matrix[4] - float64(svg.viewBox[0])
-	image.matrix[5] = float64(svg.viewBox[3]) - image.matrix[5]
-
 	if svg.group != nil {
 		image.indent = svg.group.indent + 1
 		svg.group.childs = append(svg.group.childs, &image)
 	}
+	svg.applyEffects(&image)
 
 	imgData, err := raster.B64Data()
 	if err != nil {
@@ -364,8 +432,128 @@ func (svg *SVG) SetRaster(raster *illustrator.Raster) {
 	image.SetImage(imgData)
 }
 
+func (svg *SVG) PlacedArt(obj *illustrator.PlacedArt) {
+	if obj.Embedded || len(obj.Href) == 0 {
+		return
+	}
+
+	if !svg.path.IsCompound() {
+		svg.path.Reset()
+	}
+
+	// Bounds是放置空间中图片的范围, 图片空间翻转后左上角移到Bounds的起点
+	box := illustrator.Translate(obj.Bounds[0], -obj.Bounds[1])
+	image := SvgImage{
+		id:     "<Linked File>",
+		parent: svg.group,
+		width:  obj.Bounds[2] - obj.Bounds[0],
+		height: obj.Bounds[3] - obj.Bounds[1],
+		matrix: svg.imageMatrix(box.Multiply(obj.Matrix)),
+		styles: svg.styles.nofillstroke(),
+		b64Img: obj.Href,
+		link:   obj.Path,
+	}
+
+	if svg.group != nil {
+		image.indent = svg.group.indent + 1
+		svg.group.childs = append(svg.group.childs, &image)
+	}
+	svg.applyEffects(&image)
+}
+
+// pageMatrix 从ai坐标转换到svg坐标, y轴翻转
+func (svg *SVG) pageMatrix() illustrator.Matrix {
+	return illustrator.Matrix{1, 0, 0, -1, -float64(svg.viewBox[0]), float64(svg.viewBox[3])}
+}
+
+// imageMatrix 图片的行从上到下, 先翻转图片空间再转换到svg坐标
+func (svg *SVG) imageMatrix(m illustrator.Matrix) [6]float64 {
+	flip := illustrator.Scale(1, -1)
+	return flip.Multiply(m).Multiply(svg.pageMatrix())
+}
+
+func (svg *SVG) PushTransform(m illustrator.Matrix) {
+	page := svg.pageMatrix()
+	inv, _ := page.Invert()
+	t := inv.Multiply(m).Multiply(page)
+
+	// 滤镜留给下一个元素
+	effects := svg.effects
+	svg.effects = ""
+	svg.Group()
+	svg.effects = effects
+	svg.group.SetAttr("transform", fmt.Sprintf("matrix(%s,%s,%s,%s,%s,%s)",
+		Float(t[0]), Float(t[1]), Float(t[2]), Float(t[3]), Float(t[4]), Float(t[5])))
+}
+
+func (svg *SVG) PopTransform() {
+	svg.EndGroup()
+}
+
+func (svg *SVG) Guide(g *illustrator.Guide) {
+	svg.guides = append(svg.guides, *g)
+}
+
+// Guides returns the guides of the document, they are not written to
+// the svg unless SetIncludeGuides is used
+func (svg *SVG) Guides() []illustrator.Guide {
+	return svg.guides
+}
+
+// SaveGuides writes the guides as a json list
+func (svg *SVG) SaveGuides(path string) error {
+	data, err := json.MarshalIndent(svg.guides, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func (svg *SVG) SetEffects(effects []illustrator.Effect) {
+	filter := SvgFilter{
+		id:      "filter" + strconv.Itoa(len(svg.filters)+1),
+		effects: effects,
+	}
+	svg.filters = append(svg.filters, filter)
+	svg.effects = filter.id
+}
+
+func (svg *SVG) applyEffects(node SvgNode) {
+	if len(svg.effects) == 0 {
+		return
+	}
+
+	node.SetAttr("filter", fmt.Sprintf("url(#%s)", svg.effects))
+	svg.effects = ""
+}
+
+func (svg *SVG) Mesh(m *illustrator.Mesh) {
+	if !svg.path.IsCompound() {
+		svg.path.Reset()
+	}
+
+	mesh := SvgMesh{
+		id:     "<Mesh>",
+		parent: svg.group,
+		mesh:   m,
+		styles: svg.styles.nofillstroke(),
+	}
+
+	if svg.group != nil {
+		mesh.indent = svg.group.indent + 1
+		svg.group.childs = append(svg.group.childs, &mesh)
+	}
+	svg.applyEffects(&mesh)
+}
+
 func (_svg *SVG) writeNodes(canvas *Canvas, nodes []SvgNode) {
 	for _, e := range nodes {
+		if canvas.done() {
+			return
+		}
+		canvas.nodeWritten()
+
 		switch node := e.(type) {
 		case *SvgPath:
 			node.id = canvas.nextPathId()
@@ -383,10 +571,60 @@ func (_svg *SVG) writeNodes(canvas *Canvas, nodes []SvgNode) {
 			node.id = canvas.nextImageId()
 			canvas.writeImage(node)
 			// canvas.Image()
+		case *SvgMesh:
+			node.id = canvas.nextGroupId()
+			_svg.writeMesh(canvas, node)
 		}
 	}
 }
 
+func (_svg *SVG) writeMesh(canvas *Canvas, node *SvgMesh) {
+	// svg 1.1 has no mesh primitive, approximate each patch with small polygons
+	tolerance := canvas.writeOption.MeshTolerance
+	if tolerance <= 0 {
+		tolerance = 1
+	}
+
+	vx, vy := float64(_svg.viewBox[0]), float64(_svg.viewBox[3])
+	point := func(p *illustrator.MeshPatch, u, v float64) string {
+		x, y := p.Point(u, v)
+		return Float(x-vx) + "," + Float(vy-y)
+	}
+
+	attrs := []string{Attr("id", node.id)}
+	if len(node.styles) > 0 {
+		attrs = append(attrs, Attr("style", node.styles))
+	}
+	attrs = append(attrs, node.Attrs()...)
+
+	canvas.Group(attrs...)
+	m := node.mesh
+	for row := 0; row < m.Rows; row++ {
+		for col := 0; col < m.Cols; col++ {
+			patch := m.Patch(row, col)
+			if patch == nil || canvas.done() {
+				continue
+			}
+
+			n := patch.Subdivisions(tolerance)
+			step := 1 / float64(n)
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					u0, v0 := float64(j)*step, float64(i)*step
+					u1, v1 := u0+step, v0+step
+					rgb := patch.Color(u0+step/2, v0+step/2)
+					d := "M" + point(patch, u0, v0) + "L" + point(patch, u1, v0) +
+						"L" + point(patch, u1, v1) + "L" + point(patch, u0, v1) + "z"
+					// stroke with the fill color to hide the seams between polygons
+					color := fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2])
+					canvas.Path(d, Attr("style", "fill:"+color+";stroke:"+color+";stroke-width:0.25;"))
+				}
+			}
+		}
+	}
+	canvas.Gend()
+}
+
 func (_Svg *SVG) writeClips(canvas *Canvas, group *SvgGroup) {
 	if len(group.clips) == 0 {
 		return
@@ -404,7 +642,11 @@ func (_Svg *SVG) writeClips(canvas *Canvas, group *SvgGroup) {
 	clipid := canvas.nextClipId()
 	canvas.ClipPath(Attr("id", clipid))
 	for i := range group.clips {
-		canvas.Path(group.clips[i].d)
+		if rule := group.clips[i].clipRule; len(rule) > 0 {
+			canvas.Path(group.clips[i].d, Attr("clip-rule", rule))
+		} else {
+			canvas.Path(group.clips[i].d)
+		}
 	}
 	canvas.ClipEnd()
 
@@ -413,6 +655,29 @@ func (_Svg *SVG) writeClips(canvas *Canvas, group *SvgGroup) {
 	}
 }
 
+// countNodes 统计节点及其子节点数
+func countNodes(nodes []SvgNode) int {
+	n := 0
+	for _, e := range nodes {
+		n++
+		if g, ok := e.(*SvgGroup); ok {
+			n += countNodes(g.childs)
+		}
+	}
+	return n
+}
+
+// layerNodes 统计所有层下的节点数, 不包括层本身
+func layerNodes(layers []SvgNode) int {
+	n := 0
+	for _, e := range layers {
+		if g, ok := e.(*SvgGroup); ok {
+			n += countNodes(g.childs)
+		}
+	}
+	return n
+}
+
 func (_svg *SVG) writeLayers(canvas *Canvas) {
 	for _, e := range _svg.layers {
 		node, _ := e.(*SvgGroup)
@@ -422,6 +687,30 @@ func (_svg *SVG) writeLayers(canvas *Canvas) {
 	}
 }
 
+func (_svg *SVG) writeGuides(canvas *Canvas) {
+	if !canvas.writeOption.IncludeGuides || len(_svg.guides) == 0 {
+		return
+	}
+
+	vx, vy := float64(_svg.viewBox[0]), float64(_svg.viewBox[3])
+	canvas.Group(Attr("id", "Guides"), Attr("style", "fill:none;stroke:#4AFFFF;stroke-width:0.5;"))
+	for _, g := range _svg.guides {
+		var d strings.Builder
+		for i, p := range g.Points {
+			if i == 0 {
+				d.WriteByte('M')
+			} else {
+				d.WriteByte('L')
+			}
+			d.WriteString(Float(p[0] - vx))
+			d.WriteByte(',')
+			d.WriteString(Float(vy - p[1]))
+		}
+		canvas.Path(d.String())
+	}
+	canvas.Gend()
+}
+
 func (_svg *SVG) writeGradients(canvas *Canvas) {
 	for i := range _svg.gradient.Instances {
 		instance := &_svg.gradient.Instances[i]
@@ -432,13 +721,18 @@ func (_svg *SVG) writeGradients(canvas *Canvas) {
 func (_svg *SVG) writeDefs(canvas *Canvas) {
 	canvas.Def()
 	// _svg.writeGradients(canvas)
+	for i := range _svg.filters {
+		canvas.writeFilter(&_svg.filters[i])
+	}
 	canvas.DefEnd()
 }
 
-func (_svg *SVG) writeTo(w io.Writer, writeOption *SvgWriteOption) error {
+func (_svg *SVG) writeTo(ctx context.Context, w io.Writer, writeOption *SvgWriteOption) error {
 	canvas := &Canvas{
 		SVG:         svg.New(w),
 		writeOption: writeOption,
+		ctx:         ctx,
+		total:       layerNodes(_svg.layers),
 	}
 
 	ux := _svg.viewBox[2] - _svg.viewBox[0]
@@ -447,23 +741,41 @@ func (_svg *SVG) writeTo(w io.Writer, writeOption *SvgWriteOption) error {
 	fmt.Fprintln(canvas.Writer, `<!-- Generated by LEMI -->`)
 	_svg.writeDefs(canvas)
 	_svg.writeLayers(canvas)
+	_svg.writeGuides(canvas)
 	canvas.End()
-	return nil
+	return canvas.err
 }
 
 func (svg *SVG) Save(path string, options ...func(*SvgWriteOption)) error {
+	return svg.SaveContext(context.Background(), path, options...)
+}
+
+// SaveContext 同Save, ctx取消时停止写入并返回ctx.Err()
+func (svg *SVG) SaveContext(ctx context.Context, path string, options ...func(*SvgWriteOption)) error {
 	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
+	// 链接文件默认相对于svg文件
+	options = append([]func(*SvgWriteOption){SetBaseDir(filepath.Dir(path))}, options...)
+	return svg.EncodeContext(ctx, file, options...)
+}
+
+// Encode 将svg写入w
+func (svg *SVG) Encode(w io.Writer, options ...func(*SvgWriteOption)) error {
+	return svg.EncodeContext(context.Background(), w, options...)
+}
+
+// EncodeContext 同Encode, ctx取消时停止写入并返回ctx.Err()
+func (svg *SVG) EncodeContext(ctx context.Context, w io.Writer, options ...func(*SvgWriteOption)) error {
 	var writeOption SvgWriteOption
 	for _, opt := range options {
 		opt(&writeOption)
 	}
 
-	return svg.writeTo(file, &writeOption)
+	return svg.writeTo(ctx, w, &writeOption)
 }
 
 func (svg *SVG) Nodes(depth int) []SvgNode {
@@ -507,6 +819,28 @@ type Canvas struct {
 	imageid int
 
 	writeOption *SvgWriteOption
+
+	ctx context.Context
+	err error // 写入中断的原因
+
+	written int // 已写入的节点数
+	total   int // 总节点数
+}
+
+// nodeWritten 报告写入进度
+func (c *Canvas) nodeWritten() {
+	c.written++
+	if c.writeOption.Progress != nil {
+		c.writeOption.Progress(c.written, c.total)
+	}
+}
+
+// done 检查是否已取消
+func (c *Canvas) done() bool {
+	if c.err == nil {
+		c.err = c.ctx.Err()
+	}
+	return c.err != nil
 }
 
 func (c *Canvas) nextClipId() string {
@@ -551,8 +885,31 @@ func (c *Canvas) wirteGradient(g *illustrator.Gradient) {
 	}
 }
 
+// linkHref 返回链接文件的url, 文件路径相对于dir; 已经是url的链接不变
+func linkHref(file, dir string) string {
+	if strings.Contains(file, "://") {
+		return file
+	}
+
+	if len(dir) > 0 && filepath.IsAbs(file) == filepath.IsAbs(dir) {
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			file = rel
+		}
+	}
+
+	u := url.URL{Path: filepath.ToSlash(file)}
+	if filepath.IsAbs(file) {
+		u.Scheme = "file"
+		if !strings.HasPrefix(u.Path, "/") {
+			// windows盘符
+			u.Path = "/" + u.Path
+		}
+	}
+	return u.String()
+}
+
 func (c *Canvas) writeImage(img *SvgImage) {
-	if c.writeOption.IgnoreImage {
+	if c.writeOption.IgnoreImage && len(img.link) == 0 {
 		return // skip to write image node
 	}
 	styles := "overflow:visible;" + img.styles
@@ -560,8 +917,20 @@ func (c *Canvas) writeImage(img *SvgImage) {
 		Float(img.matrix[0]), Float(img.matrix[1]), Float(img.matrix[2]),
 		Float(img.matrix[3]), Float(img.matrix[4]), Float(img.matrix[5]),
 	)
-	fmt.Fprintf(c.Writer, `<image id="%s" width="%d" height="%d" transform="%s" style="%s" href="%s"></image>`,
-		img.id, img.width, img.height, transform, styles, img.b64Img,
-	)
+	attrs := ""
+	for _, attr := range img.Attrs() {
+		attrs += " " + attr
+	}
+	if len(img.link) > 0 {
+		// keep the original link of placed art
+		href := linkHref(img.b64Img, c.writeOption.BaseDir)
+		fmt.Fprintf(c.Writer, `<image id="%s" width="%s" height="%s" transform="%s" style="%s"%s href="%s" data-link="%s"></image>`,
+			img.id, Float(img.width), Float(img.height), transform, styles, attrs, html.EscapeString(href), html.EscapeString(img.link),
+		)
+	} else {
+		fmt.Fprintf(c.Writer, `<image id="%s" width="%s" height="%s" transform="%s" style="%s"%s href="%s"></image>`,
+			img.id, Float(img.width), Float(img.height), transform, styles, attrs, img.b64Img,
+		)
+	}
 	fmt.Fprintln(c.Writer)
 }
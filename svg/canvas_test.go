@@ -0,0 +1,263 @@
+package svg
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/fpagyu/illustrator"
+)
+
+func newTestSVG() *SVG {
+	doc := &SVG{}
+	doc.SetHeader(&illustrator.AIHeader{BoundingBox: [4]int{0, 0, 100, 100}})
+	doc.BeginLayer(&illustrator.AILayer{Name: "Layer 1", Visible: true})
+	return doc
+}
+
+func encode(t *testing.T, doc *SVG) string {
+	t.Helper()
+
+	doc.EndLayer()
+	var buf bytes.Buffer
+	if err := doc.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func square(doc *SVG, x, y float64) {
+	doc.Moveto(x, y)
+	doc.Lineto(x+10, y)
+	doc.Lineto(x+10, y+10)
+	doc.ClosePath()
+}
+
+var blur = []illustrator.Effect{{Type: illustrator.EffectGaussianBlur, Name: "Blur", Blur: 2}}
+
+// filtered returns the element names using a filter
+func filtered(out string) []string {
+	var names []string
+	for _, m := range regexp.MustCompile(`<(\w+) [^>]*filter="url\(#filter1\)"`).FindAllStringSubmatch(out, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+func TestEffectsLeafNodes(t *testing.T) {
+	gs := illustrator.NewGraphicsState()
+	raster := &illustrator.Raster{
+		Matrix: illustrator.Identity(), Width: 1, Height: 1, Bits: 8, ImageType: 3,
+		RawData: []byte{255, 0, 0},
+	}
+
+	tests := []struct {
+		name string
+		draw func(doc *SVG)
+		want []string
+	}{
+		{
+			name: "raster",
+			draw: func(doc *SVG) {
+				doc.SetEffects(blur)
+				doc.SetRaster(raster)
+				square(doc, 0, 0)
+				doc.V2().PathRender(illustrator.AI_Fill, &gs)
+			},
+			want: []string{"image"},
+		},
+		{
+			name: "mesh",
+			draw: func(doc *SVG) {
+				doc.SetEffects(blur)
+				doc.Mesh(&illustrator.Mesh{})
+				square(doc, 0, 0)
+				doc.V2().PathRender(illustrator.AI_Fill, &gs)
+			},
+			want: []string{"g"},
+		},
+		{
+			name: "placed art",
+			draw: func(doc *SVG) {
+				doc.SetEffects(blur)
+				doc.PlacedArt(&illustrator.PlacedArt{
+					Path: "a.png", Href: "a.png", Matrix: illustrator.Identity(), Bounds: [4]float64{0, 0, 10, 10},
+				})
+				square(doc, 0, 0)
+				doc.V2().PathRender(illustrator.AI_Fill, &gs)
+			},
+			want: []string{"image"},
+		},
+		{
+			name: "clip path",
+			draw: func(doc *SVG) {
+				doc.Group()
+				doc.SetEffects(blur)
+				square(doc, 0, 0)
+				doc.ClipPath()
+				doc.ApplyClip()
+				doc.V2().PathRender(illustrator.AI_Fill, &gs)
+				square(doc, 20, 20)
+				doc.V2().PathRender(illustrator.AI_Fill, &gs)
+				doc.EndGroup()
+			},
+			want: []string{"path"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newTestSVG()
+			tt.draw(doc)
+			out := encode(t, doc)
+			if got := filtered(out); len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("filtered elements %v, want %v\n%s", got, tt.want, out)
+			}
+		})
+	}
+}
+
+func TestMeshAttrs(t *testing.T) {
+	doc := newTestSVG()
+	doc.V2().SetOpacity(0.5)
+	doc.Group()
+	square(doc, 0, 0)
+	doc.ClipPath()
+	doc.ApplyClip()
+	doc.PathRender(0)
+	doc.Mesh(&illustrator.Mesh{})
+	doc.EndGroup()
+
+	out := encode(t, doc)
+	mesh := regexp.MustCompile(`<g id="group2"[^>]*>`).FindString(out)
+	for _, want := range []string{`style="opacity:0.5;"`, `clip-path="url(#clip1)"`} {
+		if !strings.Contains(mesh, want) {
+			t.Errorf("mesh %s has no %s\n%s", mesh, want, out)
+		}
+	}
+}
+
+func TestLinkHref(t *testing.T) {
+	tests := []struct {
+		file, dir string
+		want      string
+	}{
+		{"art/links/photo.png", "art", "links/photo.png"},
+		{"art/photo 1.png", "out", "../art/photo%201.png"},
+		{"/data/art/a#1.png", "/data/out", "../art/a%231.png"},
+		{"/data/art/a.png", "", "file:///data/art/a.png"},
+		{"photo.png", "", "photo.png"},
+		{"https://example.com/a.png", "out", "https://example.com/a.png"},
+	}
+
+	for _, tt := range tests {
+		if got := linkHref(tt.file, tt.dir); got != tt.want {
+			t.Errorf("linkHref(%q, %q) = %q, want %q", tt.file, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestPlacedArtImage(t *testing.T) {
+	doc := newTestSVG()
+	doc.PlacedArt(&illustrator.PlacedArt{
+		Path:   `C:\art\photo.png`,
+		Href:   "art/photo.png",
+		Matrix: illustrator.Translate(10, 20),
+		Bounds: [4]float64{5, 5, 17.5, 12.25},
+	})
+	out := encode(t, doc)
+
+	// the image starts at the bounds, 5 right and 5 down of the placement
+	// origin, (15, 15) in ai coordinates, (15, 85) in the svg
+	want := `width="12.5" height="7.25" transform="matrix(1,0,0,1,15,85)"`
+	if !strings.Contains(out, want) {
+		t.Errorf("no %s in\n%s", want, out)
+	}
+	if !strings.Contains(out, `href="art/photo.png"`) {
+		t.Errorf("href is not the link\n%s", out)
+	}
+}
+
+const layerConcat = `%%EndComments
+%AI5_BeginLayer
+1 1 1 1 0 0 -1 79 128 255 Lb
+(Layer 1) Ln
+[ 1 0 0 1 10 20 ] concat
+u
+[ 2 0 0 2 0 0 ] concat
+0 0 m 10 0 L f
+U
+LB
+%AI5_EndLayer--
+%AI5_BeginLayer
+1 1 1 1 0 0 -1 79 128 255 Lb
+(Layer 2) Ln
+0 0 m 1 1 L S
+LB
+%AI5_EndLayer--
+`
+
+func TestFilterLayerTransforms(t *testing.T) {
+	r, err := illustrator.NewAIReader(strings.NewReader(layerConcat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &SVG{}
+	if err := r.DrawV2(illustrator.NewFilterDrawer(doc.V2(), illustrator.SetFilterLayers("Layer 2"))); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := doc.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if n := strings.Count(out, "<g "); n != 1 || strings.Contains(out, "transform=") {
+		t.Errorf("the filtered layer leaves groups\n%s", out)
+	}
+}
+
+func TestDrawLegacy(t *testing.T) {
+	src := "%%EndComments\n%AI5_BeginLayer\n1 1 1 1 0 0 -1 79 128 255 Lb\n(Layer 1) Ln\n" +
+		"0 1 0 0 K 1 J 0.5 w\n0 0 m 10 0 L S\nLB\n%AI5_EndLayer--\n"
+	r, err := illustrator.NewAIReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &SVG{}
+	if err := r.Draw(doc); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := doc.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"stroke-linecap:round;", "stroke-width:0.5;", "stroke:#FF00FF;"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("no %s in\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestClipRule(t *testing.T) {
+	doc := newTestSVG()
+	v := doc.V2()
+	gs := illustrator.NewGraphicsState()
+	v.Group()
+	square(doc, 0, 0)
+	v.ClipPath()
+	v.ApplyClip()
+	gs.EvenOdd = true
+	v.PathRender(0, &gs)
+	gs.EvenOdd = false
+	square(doc, 20, 20)
+	v.PathRender(illustrator.AI_Fill, &gs)
+	v.EndGroup()
+
+	out := encode(t, doc)
+	if !regexp.MustCompile(`<clipPath id="clip1"\s*>\s*<path [^>]*clip-rule="evenodd"`).MatchString(out) {
+		t.Errorf("the clipping path has no even-odd rule\n%s", out)
+	}
+}
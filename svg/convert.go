@@ -0,0 +1,61 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/fpagyu/illustrator"
+)
+
+// ConvertToSVG 将r中的ai文件转换为svg写入w, 没有illustrator私有数据的pdf
+// 按页面内容绘制
+func ConvertToSVG(r io.Reader, w io.Writer, options ...func(*SvgWriteOption)) error {
+	ra, size, err := readerAt(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := illustrator.OpenReaderAt(ra, size)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var doc SVG
+	reader, err := file.AIReader()
+	switch {
+	case err == nil:
+		err = reader.DrawV2(doc.V2())
+	case errors.Is(err, illustrator.ErrNoPrivateData) && file.PDF != nil:
+		err = file.PDF.DrawContent(doc.V2())
+	}
+	if err != nil {
+		return err
+	}
+
+	return doc.Encode(w, options...)
+}
+
+// readerAt 返回可随机读取的r, 无法随机读取时读入内存
+func readerAt(r io.Reader) (io.ReaderAt, int64, error) {
+	switch v := r.(type) {
+	case interface {
+		io.ReaderAt
+		Size() int64
+	}:
+		return v, v.Size(), nil
+	case *os.File:
+		stat, err := v.Stat()
+		if err == nil && stat.Mode().IsRegular() {
+			return v, stat.Size(), nil
+		}
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
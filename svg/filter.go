@@ -0,0 +1,53 @@
+package svg
+
+import (
+	"fmt"
+
+	"github.com/fpagyu/illustrator"
+)
+
+type SvgFilter struct {
+	id      string
+	effects []illustrator.Effect
+}
+
+func (c *Canvas) writeFilter(f *SvgFilter) {
+	fmt.Fprintf(c.Writer, `<filter id="%s" x="-50%%" y="-50%%" width="200%%" height="200%%">`, f.id)
+	fmt.Fprintln(c.Writer)
+
+	in := "SourceGraphic"
+	for i := range f.effects {
+		e := &f.effects[i]
+		result := fmt.Sprintf("effect%d", i)
+		std := Float(e.Blur / 2)
+		color := fmt.Sprintf("#%02X%02X%02X", e.Color[0], e.Color[1], e.Color[2])
+		opacity := Float(e.Opacity)
+
+		switch e.Type {
+		case illustrator.EffectDropShadow, illustrator.EffectOuterGlow:
+			fmt.Fprintf(c.Writer, `<feGaussianBlur in="%s" stdDeviation="%s" result="%s-blur"/>`, in, std, result)
+			fmt.Fprintf(c.Writer, `<feOffset in="%s-blur" dx="%s" dy="%s" result="%s-offset"/>`, result, Float(e.Dx), Float(e.Dy), result)
+			fmt.Fprintf(c.Writer, `<feFlood flood-color="%s" flood-opacity="%s"/>`, color, opacity)
+			fmt.Fprintf(c.Writer, `<feComposite in2="%s-offset" operator="in" result="%s-shadow"/>`, result, result)
+			fmt.Fprintf(c.Writer, `<feMerge result="%s"><feMergeNode in="%s-shadow"/><feMergeNode in="%s"/></feMerge>`, result, result, in)
+		case illustrator.EffectInnerGlow:
+			fmt.Fprintf(c.Writer, `<feGaussianBlur in="%s" stdDeviation="%s" result="%s-blur"/>`, in, std, result)
+			fmt.Fprintf(c.Writer, `<feComposite in="%s" in2="%s-blur" operator="out" result="%s-edge"/>`, in, result, result)
+			fmt.Fprintf(c.Writer, `<feFlood flood-color="%s" flood-opacity="%s"/>`, color, opacity)
+			fmt.Fprintf(c.Writer, `<feComposite in2="%s-edge" operator="in" result="%s-glow"/>`, result, result)
+			fmt.Fprintf(c.Writer, `<feMerge result="%s"><feMergeNode in="%s"/><feMergeNode in="%s-glow"/></feMerge>`, result, in, result)
+		case illustrator.EffectGaussianBlur:
+			fmt.Fprintf(c.Writer, `<feGaussianBlur in="%s" stdDeviation="%s" result="%s"/>`, in, std, result)
+		case illustrator.EffectFeather:
+			// blur the alpha channel and keep the source colors
+			fmt.Fprintf(c.Writer, `<feGaussianBlur in="%s" stdDeviation="%s" result="%s-blur"/>`, in, std, result)
+			fmt.Fprintf(c.Writer, `<feComposite in="%s" in2="%s-blur" operator="in" result="%s"/>`, in, result, result)
+		default:
+			continue
+		}
+		fmt.Fprintln(c.Writer)
+		in = result
+	}
+
+	fmt.Fprintln(c.Writer, `</filter>`)
+}
@@ -5,14 +5,17 @@ type SvgImage struct {
 	indent int
 	parent *SvgGroup
 
-	width  int
-	height int
+	width  float64
+	height float64
 	matrix [6]float64
 
 	styles string
 
 	// data []byte
 	b64Img string
+	link   string // 链接图片的原始路径
+
+	attrs map[string]string
 }
 
 func (si *SvgImage) Id() string {
@@ -23,7 +26,20 @@ func (si *SvgImage) Indent() int {
 	return si.indent
 }
 
-func (si *SvgImage) SetAttr(key, val string) {
+func (si *SvgImage) SetAttr(k, v string) {
+	if len(k) == 0 || len(v) == 0 {
+		return
+	}
+
+	if si.attrs == nil {
+		si.attrs = make(map[string]string)
+	}
+	si.attrs[k] = v
+}
+
+// Attrs 返回clip-path, filter等属性, 按名称排序
+func (si *SvgImage) Attrs() []string {
+	return sortedAttrs(si.attrs)
 }
 
 func (si *SvgImage) SetImage(b64data string) {
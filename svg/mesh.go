@@ -0,0 +1,38 @@
+package svg
+
+import "github.com/fpagyu/illustrator"
+
+type SvgMesh struct {
+	id     string
+	indent int
+	parent *SvgGroup
+
+	mesh   *illustrator.Mesh
+	styles string
+
+	attrs map[string]string
+}
+
+func (sm *SvgMesh) Id() string {
+	return sm.id
+}
+
+func (sm *SvgMesh) Indent() int {
+	return sm.indent
+}
+
+func (sm *SvgMesh) SetAttr(k, v string) {
+	if len(k) == 0 || len(v) == 0 {
+		return
+	}
+
+	if sm.attrs == nil {
+		sm.attrs = make(map[string]string)
+	}
+	sm.attrs[k] = v
+}
+
+// Attrs 返回clip-path, filter等属性, 按名称排序
+func (sm *SvgMesh) Attrs() []string {
+	return sortedAttrs(sm.attrs)
+}
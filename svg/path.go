@@ -12,8 +12,9 @@ type SvgPath struct {
 
 	pathOp illustrator.PathOp
 
-	d     string
-	attrs map[string]string
+	d        string
+	attrs    map[string]string
+	clipRule string // 作为剪切路径的规则, 空为nonzero
 }
 
 type SvgCompoundPath struct {
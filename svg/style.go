@@ -1,7 +1,10 @@
 package svg
 
 import (
+	"fmt"
 	"strings"
+
+	"github.com/fpagyu/illustrator"
 )
 
 type StyleBuild map[string]string
@@ -69,3 +72,56 @@ func (b StyleBuild) nofillstroke() string {
 
 	return w.String()
 }
+
+func colorHex(c illustrator.Color) string {
+	rgb := c.ToRGB()
+	return fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2])
+}
+
+// stateStyle returns the styles of a path painted with gs
+func stateStyle(gs *illustrator.GraphicsState) StyleBuild {
+	b := StyleBuild{
+		"fill":         colorHex(gs.Fill),
+		"stroke":       colorHex(gs.Stroke),
+		"stroke-width": Float(gs.LineWidth),
+	}
+
+	switch gs.LineCap {
+	case illustrator.RoundCap:
+		b["stroke-linecap"] = "round"
+	case illustrator.SquareCap:
+		b["stroke-linecap"] = "square"
+	}
+
+	switch gs.LineJoin {
+	case illustrator.RoundJoin:
+		b["stroke-linejoin"] = "round"
+	case illustrator.BevelJoin:
+		b["stroke-linejoin"] = "bevel"
+	}
+
+	if gs.MiterLimit != 4 {
+		b["stroke-miterlimit"] = Float(gs.MiterLimit)
+	}
+
+	if len(gs.Dash) > 0 {
+		dash := make([]string, len(gs.Dash))
+		for i, v := range gs.Dash {
+			dash[i] = Float(v)
+		}
+		b["stroke-dasharray"] = strings.Join(dash, ",")
+		if gs.DashPhase != 0 {
+			b["stroke-dashoffset"] = Float(gs.DashPhase)
+		}
+	}
+
+	if gs.EvenOdd {
+		b["fill-rule"] = "evenodd"
+	}
+
+	if gs.Opacity != 1 {
+		b["opacity"] = Float(gs.Opacity)
+	}
+
+	return b
+}
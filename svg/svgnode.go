@@ -1,6 +1,9 @@
 package svg
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 type SvgNode interface {
 	Id() string
@@ -11,3 +14,18 @@ type SvgNode interface {
 func Attr(k, v string) string {
 	return fmt.Sprintf(`%s="%s"`, k, v)
 }
+
+// sortedAttrs 按名称排序输出属性, 保证输出稳定
+func sortedAttrs(attrs map[string]string) []string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	r := make([]string, len(keys))
+	for i, k := range keys {
+		r[i] = Attr(k, attrs[k])
+	}
+	return r
+}
@@ -1,7 +1,12 @@
 package svg
 
 type SvgWriteOption struct {
-	IgnoreImage bool // 忽略位图数据, 保存为svg的时候, image数据不会写入
+	IgnoreImage   bool    // 忽略位图数据, 保存为svg的时候, image数据不会写入
+	MeshTolerance float64 // 渐变网格细分的最大多边形尺寸, 默认为1
+	IncludeGuides bool    // 输出参考线, 默认不输出
+	BaseDir       string  // 链接文件的href相对于此目录, Save时为svg文件所在的目录
+
+	Progress func(written, total int) // 写入进度, 已写入的节点数和总节点数
 }
 
 func SetIgnoreImage(v bool) func(*SvgWriteOption) {
@@ -9,3 +14,27 @@ func SetIgnoreImage(v bool) func(*SvgWriteOption) {
 		swo.IgnoreImage = v
 	}
 }
+
+func SetMeshTolerance(v float64) func(*SvgWriteOption) {
+	return func(swo *SvgWriteOption) {
+		swo.MeshTolerance = v
+	}
+}
+
+func SetIncludeGuides(v bool) func(*SvgWriteOption) {
+	return func(swo *SvgWriteOption) {
+		swo.IncludeGuides = v
+	}
+}
+
+func SetProgress(fn func(written, total int)) func(*SvgWriteOption) {
+	return func(swo *SvgWriteOption) {
+		swo.Progress = fn
+	}
+}
+
+func SetBaseDir(dir string) func(*SvgWriteOption) {
+	return func(swo *SvgWriteOption) {
+		swo.BaseDir = dir
+	}
+}
@@ -0,0 +1,80 @@
+package illustrator
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+var ErrNoThumbnail = errors.New("illustrator: no thumbnail")
+
+// ThumbnailJPEG returns the jpeg thumbnail of the xmp metadata, as stored
+// in the file
+func (r *Reader) ThumbnailJPEG() ([]byte, error) {
+	meta, err := r.Metadata()
+	if err != nil {
+		if errors.Is(err, ErrNoMetadata) {
+			return nil, ErrNoThumbnail
+		}
+		return nil, err
+	}
+
+	for _, t := range meta.Thumbnails {
+		if t.Format == "JPEG" && len(t.Image) > 0 {
+			return t.Data()
+		}
+	}
+	return nil, ErrNoThumbnail
+}
+
+// Thumbnail returns the thumbnail of the xmp metadata, or the /Thumb
+// image of the page when the metadata has none or can not be read. The
+// error of the metadata is returned when the page has no thumbnail either
+func (r *Reader) Thumbnail() (image.Image, error) {
+	data, err := r.ThumbnailJPEG()
+	if err == nil {
+		var img image.Image
+		if img, err = jpeg.Decode(bytes.NewReader(data)); err == nil {
+			return img, nil
+		}
+	}
+
+	img, pageErr := r.pageThumbnail()
+	if pageErr == nil {
+		return img, nil
+	}
+	if errors.Is(pageErr, ErrNoThumbnail) && !errors.Is(err, ErrNoThumbnail) {
+		return nil, err
+	}
+	return nil, pageErr
+}
+
+func (r *Reader) pageThumbnail() (image.Image, error) {
+	stream, ok := core.GetStream(r.page.Thumb)
+	if !ok {
+		return nil, ErrNoThumbnail
+	}
+
+	ximg, err := model.NewXObjectImageFromStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := ximg.ToImage()
+	if err != nil {
+		return nil, err
+	}
+
+	if ximg.ColorSpace != nil {
+		rgb, err := ximg.ColorSpace.ImageToRGB(*img)
+		if err != nil {
+			return nil, err
+		}
+		img = &rgb
+	}
+	return img.ToGoImage()
+}
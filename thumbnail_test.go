@@ -0,0 +1,63 @@
+package illustrator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// thumbPDF returns a one page pdf with the xmp packet and a 2x1 /Thumb
+// image of a red and a blue pixel, without /Thumb when thumb is false
+func thumbPDF(xmp string, thumb bool) []byte {
+	page := "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] >>"
+	if thumb {
+		page = "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] /Thumb 5 0 R >>"
+	}
+	pixels := "\xff\x00\x00\x00\x00\xff"
+	return testPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 4 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		page,
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(xmp), xmp),
+		fmt.Sprintf("<< /Width 2 /Height 1 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Length %d >>\nstream\n%s\nendstream", len(pixels), pixels),
+	}, false)
+}
+
+func TestThumbnailFallback(t *testing.T) {
+	const brokenXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF`
+	const emptyXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/"></x:xmpmeta>`
+
+	for _, xmp := range []string{brokenXMP, emptyXMP} {
+		r, err := NewBytesReader(thumbPDF(xmp, true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := r.Thumbnail()
+		if err != nil {
+			t.Fatalf("xmp %q: %v", xmp, err)
+		}
+		if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+			t.Errorf("got a %v thumbnail, want 2x1", b)
+		}
+		if red, _, blue, _ := img.At(0, 0).RGBA(); red != 0xffff || blue != 0 {
+			t.Errorf("first pixel %v, want red", img.At(0, 0))
+		}
+	}
+
+	// the error of the metadata when the page has no thumbnail
+	r, err := NewBytesReader(thumbPDF(brokenXMP, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Thumbnail(); err == nil || errors.Is(err, ErrNoThumbnail) {
+		t.Errorf("got %v, want the xmp error", err)
+	}
+
+	r, err = NewBytesReader(thumbPDF(emptyXMP, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Thumbnail(); !errors.Is(err, ErrNoThumbnail) {
+		t.Errorf("got %v, want %v", err, ErrNoThumbnail)
+	}
+}
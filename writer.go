@@ -0,0 +1,313 @@
+package illustrator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+var ErrStartXref = errors.New("illustrator: startxref not found")
+
+type WriteOption struct {
+	Encoding  string // registered encoding of the private data, default zlib
+	ChunkSize int    // bytes per AIPrivateDataN stream, 0 for 64KB
+}
+
+// SetEncoding sets the encoding of the private data: zlib, zstd, none or
+// an encoding added by RegisterEncoding
+func SetEncoding(name string) func(*WriteOption) {
+	return func(opt *WriteOption) {
+		opt.Encoding = name
+	}
+}
+
+func SetChunkSize(n int) func(*WriteOption) {
+	return func(opt *WriteOption) {
+		opt.ChunkSize = n
+	}
+}
+
+// WritePrivateData writes the file with its private data replaced by data
+// to w. The pdf is copied and the new private data is appended as an
+// incremental update, the pdf content and the other objects are kept.
+// The source of r must still be readable, a reader of NewFileReader is
+// written before Close.
+func (r *Reader) WritePrivateData(w io.Writer, data io.Reader, options ...func(*WriteOption)) error {
+	opt := WriteOption{Encoding: "zlib"}
+	for _, o := range options {
+		o(&opt)
+	}
+
+	enc, ok := LookupEncoding(opt.Encoding)
+	if !ok {
+		return ErrUnsupportedCompression
+	}
+
+	if encrypted, _ := r.IsEncrypted(); encrypted {
+		return ErrEncrypted
+	}
+
+	private := r.getPrivate()
+	if private == nil {
+		return ErrNoPrivateData
+	}
+
+	compressed, err := enc.New().Compress(data)
+	if err != nil {
+		return err
+	}
+	if opt.ChunkSize <= 0 {
+		opt.ChunkSize = privateChunkSize
+	}
+	// the first stream starts with the header of the registered encoding
+	chunks := splitChunks(append([]byte(enc.Header), compressed...), opt.ChunkSize)
+
+	trailer, err := r.GetTrailer()
+	if err != nil {
+		return err
+	}
+	size, ok := core.GetIntVal(trailer.Get("Size"))
+	if !ok {
+		return errors.New("illustrator: invalid trailer size")
+	}
+
+	// the new private dictionary, other keys such as AIMetaData are kept
+	newPrivate := core.MakeDict()
+	for _, key := range private.Keys() {
+		newPrivate.Set(key, private.Get(key))
+	}
+	newPrivate.Remove("NumBlock")
+	for _, key := range walkPrivateData(private).keys {
+		newPrivate.Remove(key)
+	}
+
+	newPrivate.Set("NumBlock", core.MakeInteger(int64(len(chunks))))
+	for i := range chunks {
+		ref := &core.PdfObjectReference{ObjectNumber: int64(size + i)}
+		newPrivate.Set(core.PdfObjectName(fmt.Sprintf("AIPrivateData%d", i+1)), ref)
+	}
+
+	// the object holding the private dictionary is replaced
+	illustrator := r.GetIllustrator()
+	illustratorDict, _ := core.GetDict(illustrator.PdfObject)
+	var container *core.PdfIndirectObject
+	if ind, ok := illustratorDict.Get("Private").(*core.PdfIndirectObject); ok {
+		container = &core.PdfIndirectObject{PdfObjectReference: ind.PdfObjectReference, PdfObject: newPrivate}
+	} else if illustrator.ObjectNumber == 0 {
+		return errors.New("illustrator: inline piece info can not be updated")
+	} else {
+		// the private dictionary is inline
+		dict := core.MakeDict()
+		for _, key := range illustratorDict.Keys() {
+			dict.Set(key, illustratorDict.Get(key))
+		}
+		dict.Set("Private", newPrivate)
+		container = &core.PdfIndirectObject{PdfObjectReference: illustrator.PdfObjectReference, PdfObject: dict}
+	}
+
+	u := &pdfUpdate{}
+	if err := u.copy(w, r.src); err != nil {
+		return err
+	}
+
+	for i, chunk := range chunks {
+		u.object(int64(size+i), 0, fmt.Sprintf("<</Length %d>>\nstream\n", len(chunk)), chunk, "\nendstream")
+	}
+	u.object(container.ObjectNumber, container.GenerationNumber, container.PdfObject.WriteString(), nil, "")
+
+	return u.finish(trailer, int64(size+len(chunks)))
+}
+
+// pdfUpdate writes an incremental update after a copy of the pdf,
+// unipdf's PdfWriter and PdfAppender fail without a license key
+type pdfUpdate struct {
+	w       io.Writer
+	err     error
+	offset  int64
+	prev    int64 // startxref of the copied pdf
+	xrefStm bool  // the copied pdf uses a cross-reference stream
+	entries map[int64]xrefEntry
+}
+
+type xrefEntry struct {
+	offset int64
+	gen    int64
+}
+
+func (u *pdfUpdate) write(p []byte) {
+	if u.err != nil {
+		return
+	}
+	n, err := u.w.Write(p)
+	u.offset += int64(n)
+	u.err = err
+}
+
+// copy copies the pdf and reads its startxref
+func (u *pdfUpdate) copy(w io.Writer, src io.ReadSeeker) error {
+	u.w = w
+	u.entries = map[int64]xrefEntry{}
+
+	size, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	tail := int64(1024)
+	if tail > size {
+		tail = size
+	}
+	buf := make([]byte, tail)
+	if _, err := src.Seek(size-tail, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(src, buf); err != nil {
+		return err
+	}
+
+	i := bytes.LastIndex(buf, []byte("startxref"))
+	if i < 0 {
+		return ErrStartXref
+	}
+	fields := bytes.Fields(buf[i+9:])
+	if len(fields) == 0 {
+		return ErrStartXref
+	}
+	u.prev, err = strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil || u.prev >= size {
+		return ErrStartXref
+	}
+
+	head := make([]byte, 4)
+	if _, err := src.Seek(u.prev, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(src, head); err != nil {
+		return err
+	}
+	u.xrefStm = string(head) != "xref"
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		return err
+	}
+	u.offset = n
+
+	if buf[len(buf)-1] != '\n' && buf[len(buf)-1] != '\r' {
+		u.write([]byte("\n"))
+	}
+	return u.err
+}
+
+func (u *pdfUpdate) object(num, gen int64, head string, stream []byte, tail string) {
+	u.entries[num] = xrefEntry{offset: u.offset, gen: gen}
+	u.write([]byte(fmt.Sprintf("%d %d obj\n%s", num, gen, head)))
+	u.write(stream)
+	u.write([]byte(tail + "\nendobj\n"))
+}
+
+// finish writes the cross-reference section and the trailer, in the kind
+// of the copied pdf
+func (u *pdfUpdate) finish(trailer *core.PdfObjectDictionary, size int64) error {
+	dict := core.MakeDict()
+	for _, key := range trailer.Keys() {
+		switch key {
+		case "Size", "Prev", "XRefStm", "Type", "W", "Index", "Filter", "DecodeParms", "Length":
+			continue
+		}
+		dict.Set(key, trailer.Get(key))
+	}
+	dict.Set("Prev", core.MakeInteger(u.prev))
+
+	if u.xrefStm {
+		// the cross-reference stream is an object of its own
+		num := size
+		size++
+		u.entries[num] = xrefEntry{offset: u.offset}
+		dict.Set("Size", core.MakeInteger(size))
+		u.writeXrefStream(num, dict)
+	} else {
+		dict.Set("Size", core.MakeInteger(size))
+		u.writeXrefTable(dict)
+	}
+	return u.err
+}
+
+// subsections groups the object numbers into contiguous runs
+func (u *pdfUpdate) subsections() [][]int64 {
+	nums := make([]int64, 0, len(u.entries))
+	for num := range u.entries {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+
+	var sections [][]int64
+	for i, num := range nums {
+		if i == 0 || num != nums[i-1]+1 {
+			sections = append(sections, nil)
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], num)
+	}
+	return sections
+}
+
+func (u *pdfUpdate) writeXrefTable(trailer *core.PdfObjectDictionary) {
+	start := u.offset
+
+	var b strings.Builder
+	b.WriteString("xref\n")
+	for _, section := range u.subsections() {
+		fmt.Fprintf(&b, "%d %d\n", section[0], len(section))
+		for _, num := range section {
+			e := u.entries[num]
+			fmt.Fprintf(&b, "%010d %05d n \n", e.offset, e.gen)
+		}
+	}
+	fmt.Fprintf(&b, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer.WriteString(), start)
+	u.write([]byte(b.String()))
+}
+
+func (u *pdfUpdate) writeXrefStream(num int64, dict *core.PdfObjectDictionary) {
+	start := u.offset
+
+	// offsets of 4 GiB and more take 8 bytes
+	width := 4
+	for _, e := range u.entries {
+		if e.offset>>32 > 0 {
+			width = 8
+		}
+	}
+
+	var index []core.PdfObject
+	var data bytes.Buffer
+	offset := make([]byte, 8)
+	for _, section := range u.subsections() {
+		index = append(index, core.MakeInteger(section[0]), core.MakeInteger(int64(len(section))))
+		for _, n := range section {
+			e := u.entries[n]
+			data.WriteByte(1)
+			binary.BigEndian.PutUint64(offset, uint64(e.offset))
+			data.Write(offset[8-width:])
+			binary.Write(&data, binary.BigEndian, uint16(e.gen))
+		}
+	}
+
+	dict.Set("Type", core.MakeName("XRef"))
+	dict.Set("W", core.MakeArray(core.MakeInteger(1), core.MakeInteger(int64(width)), core.MakeInteger(2)))
+	dict.Set("Index", core.MakeArray(index...))
+	dict.Set("Length", core.MakeInteger(int64(data.Len())))
+
+	u.write([]byte(fmt.Sprintf("%d 0 obj\n%s\nstream\n", num, dict.WriteString())))
+	u.write(data.Bytes())
+	u.write([]byte(fmt.Sprintf("\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", start)))
+}
@@ -0,0 +1,129 @@
+package illustrator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestWritePrivateData(t *testing.T) {
+	ps := []byte(strings.Replace(samplePS, "(Layer 1)", "(Edited)", 1))
+
+	for _, xrefStream := range []bool{false, true} {
+		for _, enc := range []string{"zlib", "zstd", "none"} {
+			name := enc + " xref table"
+			if xrefStream {
+				name = enc + " xref stream"
+			}
+			t.Run(name, func(t *testing.T) {
+				src := aiPDF(splitData(encodePS(t, "zlib", []byte(samplePS)), 3), xrefStream)
+				r, err := NewBytesReader(src)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var out bytes.Buffer
+				if err := r.WritePrivateData(&out, bytes.NewReader(ps), SetEncoding(enc), SetChunkSize(64)); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.HasPrefix(out.Bytes(), src) {
+					t.Error("the source pdf is not kept before the update")
+				}
+
+				r, err = NewReader(bytes.NewReader(out.Bytes()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := r.GetAIPrivateData()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, ps) {
+					t.Errorf("got private data\n%s\nwant\n%s", got, ps)
+				}
+
+				rep, err := r.Inspect()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !rep.OK() || rep.Encoding != enc {
+					t.Errorf("report of the update:\n%s", rep)
+				}
+			})
+		}
+	}
+}
+
+func TestFileReaderWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.ai")
+	if err := os.WriteFile(path, aiPDF([][]byte{encodePS(t, "zlib", []byte(samplePS))}, false), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewFileReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var out bytes.Buffer
+	if err := r.WritePrivateData(&out, strings.NewReader(samplePS)); err != nil {
+		t.Fatalf("write after NewFileReader: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestWriteRegisteredHeader(t *testing.T) {
+	RegisterEncoding("test-zlib", "%TEST_CompressedData", func() CompressHandle { return &ZlibCompress{} })
+
+	r, err := NewBytesReader(aiPDF([][]byte{encodePS(t, "zlib", []byte(samplePS))}, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := r.WritePrivateData(&out, strings.NewReader(samplePS), SetEncoding("test-zlib")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = NewBytesReader(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := r.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Encoding != "test-zlib" || !rep.OK() {
+		t.Errorf("report of the registered encoding:\n%s", rep)
+	}
+}
+
+func TestXrefStreamLargeOffsets(t *testing.T) {
+	var out bytes.Buffer
+	u := &pdfUpdate{w: &out, offset: 5 << 32, entries: map[int64]xrefEntry{
+		7: {offset: 1 << 32},
+		8: {offset: 5 << 32},
+	}}
+	u.writeXrefStream(8, core.MakeDict())
+	if u.err != nil {
+		t.Fatal(u.err)
+	}
+
+	if !strings.Contains(out.String(), "/W [1 8 2]") {
+		t.Fatalf("no 8 byte offsets in\n%q", out.String())
+	}
+	i := bytes.Index(out.Bytes(), []byte("stream\n")) + len("stream\n")
+	want := []byte{1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 5, 0, 0, 0, 0, 0, 0}
+	if got := out.Bytes()[i : i+len(want)]; !bytes.Equal(got, want) {
+		t.Errorf("got entries % x, want % x", got, want)
+	}
+}